@@ -0,0 +1,84 @@
+{{ define "content" }}
+
+<h1>Coordinate overrides</h1>
+
+<p>
+	Coordinates set here take precedence over the ones found by the geocoder. They are kept apart from the coordinates
+	cache and therefore survive updates and rebuilds of the cache. Overrides of location name variants are set on their
+	canonical name.
+</p>
+
+<h2>Set override</h2>
+<form action="/admin/coordinates" method="post">
+	<div class="row">
+		<div class="medium-4 columns"><input type="text" name="name" placeholder="Location name" required></div>
+		<div class="medium-2 columns"><input type="text" name="lat" placeholder="Latitude" required></div>
+		<div class="medium-2 columns"><input type="text" name="lng" placeholder="Longitude" required></div>
+		<div class="medium-3 columns"><input type="text" name="note" placeholder="Note"></div>
+		<div class="medium-1 columns"><button class="button small" name="action" value="set">Set</button></div>
+	</div>
+</form>
+
+<h2>Import from CSV</h2>
+<p>
+	Records have the form <code>location name,latitude,longitude[,note]</code>. A header line is skipped.
+</p>
+<form action="/admin/coordinates" method="post" enctype="multipart/form-data">
+	<input type="file" name="csv" accept=".csv,text/csv" required>
+	<button class="button small" name="action" value="import">Import</button>
+</form>
+
+<h2>Overrides</h2>
+{{ if .Overrides }}
+	<table>
+		<tr>
+			<th>Location name</th>
+			<th>Coordinates</th>
+			<th>Note</th>
+			<th>Updated by</th>
+			<th>Updated at</th>
+			<th></th>
+		</tr>
+		{{ range .Overrides }}
+			<tr>
+				<td>{{ .LocationName }}</td>
+				<td>{{ .Coordinates.Lat }}, {{ .Coordinates.Lng }}</td>
+				<td>{{ .Note }}</td>
+				<td>{{ .UpdatedBy }}</td>
+				<td>{{ .UpdatedAt }}</td>
+				<td>
+					<form action="/admin/coordinates" method="post">
+						<input type="hidden" name="name" value="{{ .LocationName }}">
+						<button class="button tiny alert" name="action" value="clear">Clear</button>
+					</form>
+				</td>
+			</tr>
+		{{ end }}
+	</table>
+{{ else }}
+	<p>No coordinates are overridden.</p>
+{{ end }}
+
+<h2>Recent changes</h2>
+<table>
+	<tr>
+		<th>Time</th>
+		<th>User</th>
+		<th>Action</th>
+		<th>Location name</th>
+		<th>Coordinates</th>
+		<th>Note</th>
+	</tr>
+	{{ range .Changes }}
+		<tr>
+			<td>{{ .ChangedAt }}</td>
+			<td>{{ .User }}</td>
+			<td>{{ .Action }}</td>
+			<td>{{ .LocationName }}</td>
+			<td>{{ .Coordinates.Lat }}, {{ .Coordinates.Lng }}</td>
+			<td>{{ .Note }}</td>
+		</tr>
+	{{ end }}
+</table>
+
+{{ end }}
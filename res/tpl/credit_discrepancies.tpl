@@ -0,0 +1,37 @@
+{{ define "content" }}
+
+<h1>Credit discrepancies</h1>
+
+<p>
+	Fields of movies where the data set (DataSF) and the metadata provider disagree. Suspicious discrepancies (like
+	release years that are more than a year apart or directors that the provider doesn't credit) are listed first and
+	likely point to wrong upstream data or a wrong match of the movie. The value of each field is taken from the source
+	with the highest priority (configured as <code>credit_source_priority</code>).
+</p>
+
+{{ if . }}
+	<table>
+		<tr>
+			<th>Movie</th>
+			<th>Field</th>
+			<th>DataSF</th>
+			<th>Provider</th>
+			<th>Discrepancy</th>
+			<th>Shown</th>
+		</tr>
+		{{ range . }}
+			<tr>
+				<td><a href="/movie/{{ .MovieId }}">{{ .Movie.Title }}</a>{{ if .Movie.ReleaseYear }} ({{ .Movie.ReleaseYear }}){{ end }}</td>
+				<td>{{ .Field.Field }}</td>
+				<td>{{ join .Field.DataSf }}</td>
+				<td>{{ join .Field.Metadata }} <small>({{ .Provider }})</small></td>
+				<td>{{ if .Field.Suspicious }}<b>Suspicious:</b> {{ end }}{{ .Field.Discrepancy }}</td>
+				<td>{{ with .Field.Source }}{{ source . }}{{ end }}</td>
+			</tr>
+		{{ end }}
+	</table>
+{{ else }}
+	<p>The sources agree on all movies with metadata.</p>
+{{ end }}
+
+{{ end }}
@@ -0,0 +1,40 @@
+{{ define "content" }}
+
+<h1>Geocode failures</h1>
+
+<p>
+	Location names whose coordinates could not be fetched are not looked up again until their retry time has passed.
+	Clearing a failure makes the name be looked up on the next visit to the page of a movie with that location.
+</p>
+
+{{ $now := .Now }}
+{{ if .Failures }}
+	<form action="/admin/geocode-failures" method="post">
+		<table>
+			<tr>
+				<th></th>
+				<th>Location name</th>
+				<th>Reason</th>
+				<th>Attempts</th>
+				<th>Last failure</th>
+				<th>Retry after</th>
+			</tr>
+			{{ range .Failures }}
+				<tr>
+					<td><input type="checkbox" name="name" value="{{ .LocationName }}"></td>
+					<td>{{ .LocationName }}</td>
+					<td>{{ .Reason }}</td>
+					<td>{{ .Attempts }}</td>
+					<td>{{ .FailedAt }}</td>
+					<td>{{ .RetryAt }}{{ if .Expired $now }} (expired){{ end }}</td>
+				</tr>
+			{{ end }}
+		</table>
+		<button class="button small" name="action" value="clear">Clear selected</button>
+		<button class="button small alert" name="action" value="clear-all">Clear all</button>
+	</form>
+{{ else }}
+	<p>No geocode failures are recorded.</p>
+{{ end }}
+
+{{ end }}
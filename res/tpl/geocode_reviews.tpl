@@ -0,0 +1,60 @@
+{{ define "content" }}
+
+<h1>Geocode reviews</h1>
+
+<p>
+	Geocodes are queued for review if the query had several results or only matched part of the location name (like a
+	parenthesized landmark or the neighborhood). The first candidate has been cached in the meantime. Pick the right
+	candidate, click the map (or type coordinates) to correct it, or reject all candidates.
+</p>
+
+{{ if .Pending }}
+	{{ range .Pending }}
+		<div class="callout review">
+			<h4>{{ .LocationName }} <small>{{ .Reason }}, {{ .CreatedAt }}</small></h4>
+			<div class="row">
+				<div class="medium-6 columns">
+					<div class="review-map" style="width:100%;height:300px"></div>
+				</div>
+				<div class="medium-6 columns">
+					{{ $name := .LocationName }}
+					<table>
+						{{ range $i, $c := .Candidates }}
+							<tr class="candidate" data-label="{{ label $i }}" data-lat="{{ .Coordinates.Lat }}" data-lng="{{ .Coordinates.Lng }}">
+								<td>{{ label $i }}</td>
+								<td>
+									{{ .FormattedAddress }}
+									<br>
+									<small>{{ .Source }}: '{{ .Query }}'{{ if .LocationType }} &middot; {{ .LocationType }}{{ end }}</small>
+								</td>
+								<td>
+									<form action="/admin/geocode-reviews" method="post">
+										<input type="hidden" name="name" value="{{ $name }}">
+										<input type="hidden" name="candidate" value="{{ $i }}">
+										<button class="button tiny success" name="action" value="pick">Pick</button>
+									</form>
+								</td>
+							</tr>
+						{{ end }}
+					</table>
+					<form action="/admin/geocode-reviews" method="post">
+						<input type="hidden" name="name" value="{{ .LocationName }}">
+						<div class="row">
+							<div class="small-4 columns"><input type="text" name="lat" class="correction-lat" placeholder="Latitude"></div>
+							<div class="small-4 columns"><input type="text" name="lng" class="correction-lng" placeholder="Longitude"></div>
+							<div class="small-4 columns"><button class="button tiny" name="action" value="correct">Correct</button></div>
+						</div>
+						<button class="button tiny alert" name="action" value="reject">Reject all</button>
+					</form>
+				</div>
+			</div>
+		</div>
+	{{ end }}
+	
+	<script src="/review-map.js"></script>
+	<script async defer src="https://maps.googleapis.com/maps/api/js?key={{ maps_api_key }}&callback=initReviewMaps"></script>
+{{ else }}
+	<p>No geocodes are pending review.</p>
+{{ end }}
+
+{{ end }}
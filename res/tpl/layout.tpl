@@ -10,11 +10,9 @@ END LOGS {{ comment_end }}
 	<title>SF Movies {{ if .Subtitle }} | {{ .Subtitle }} {{ end }}</title>
 	
 	<link rel="stylesheet" href="https://cdn.jsdelivr.net/foundation/6.2.4/foundation.min.css">
-	<link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/chosen/1.6.2/chosen.css">
 	
 	<script src="https://code.jquery.com/jquery-3.1.1.min.js" integrity="sha256-hVVnYaiADRTO2PzUGmuLJr8BLUSjGIZsDYGmIJLv2b8=" crossorigin="anonymous"></script>
 	<script src="https://cdn.jsdelivr.net/foundation/6.2.4/foundation.min.js"></script>
-	<script src="https://cdnjs.cloudflare.com/ajax/libs/chosen/1.6.2/chosen.jquery.min.js"></script>
 	<script src="/autocomplete.js"></script>
 	
 	<script>
@@ -39,7 +37,7 @@ END LOGS {{ comment_end }}
 			<div class="top-bar-right">
 				<ul class="menu">
 					<li>
-						<span id="loading">loading movies...</span>
+						<span id="loading">loading search...</span>
 					</li>
 				</ul>
 			</div>
@@ -0,0 +1,61 @@
+{{ define "content" }}
+
+<h1>Location name merges</h1>
+
+<p>
+	Variants of the same location name are merged into a canonical name such that they share coordinates. Strong matches
+	are merged automatically while weak ones are listed below for review.
+</p>
+
+<h2>Pending review</h2>
+{{ if .Pending }}
+	<table>
+		<tr>
+			<th>Variant</th>
+			<th>Canonical name</th>
+			<th>Similarity</th>
+			<th></th>
+		</tr>
+		{{ range .Pending }}
+			<tr>
+				<td>{{ .Alias }}</td>
+				<td>{{ .Canonical }}</td>
+				<td>{{ percent .Score }}</td>
+				<td>
+					<form action="/admin/merges" method="post">
+						<input type="hidden" name="alias" value="{{ .Alias }}">
+						<button class="button tiny success" name="action" value="accept">Merge</button>
+						<button class="button tiny alert" name="action" value="reject">Reject</button>
+					</form>
+				</td>
+			</tr>
+		{{ end }}
+	</table>
+{{ else }}
+	<p>No merges are pending review.</p>
+{{ end }}
+
+<h2>Merged</h2>
+<table>
+	<tr>
+		<th>Variant</th>
+		<th>Canonical name</th>
+		<th>Similarity</th>
+		<th></th>
+	</tr>
+	{{ range .Merged }}
+		<tr>
+			<td>{{ .Alias }}</td>
+			<td>{{ .Canonical }}</td>
+			<td>{{ percent .Score }}</td>
+			<td>
+				<form action="/admin/merges" method="post">
+					<input type="hidden" name="alias" value="{{ .Alias }}">
+					<button class="button tiny alert" name="action" value="reject">Unmerge</button>
+				</form>
+			</td>
+		</tr>
+	{{ end }}
+</table>
+
+{{ end }}
@@ -1,6 +1,6 @@
 {{ define "content" }}
 
-<h1>{{ .Movie.Title }}</h1>
+<h1>{{ .Movie.Title }} {{ if .Movie.ReleaseYear }}<small>({{ .Movie.ReleaseYear }})</small>{{ end }}</h1>
 
 <ul class="tabs" data-tabs id="movie-tabs">
 	<li class="tabs-title is-active"><a href="#tab-map" aria-selected="true">Map</a></li>
@@ -14,11 +14,28 @@
 				<div id="map" style="width:100%;height:600px"></div>
 			</div>
 			<div class="medium-4 columns">
-				<h5>{{ len .Movie.Locations }} location(s)</h5>
+				<h5>{{ len .Movie.Locations }} location(s) <small><a href="/api/v1/movies/{{ .ApiMovieId }}/locations.geojson">GeoJSON</a></small></h5>
 				<div style="height:600px;overflow:auto">
 					{{ range .Movie.Locations }}
-						<div class="callout location" data-name="{{ .Name }}" data-lat="{{ .Coordinates.Lat }}" data-lng="{{ .Coordinates.Lng }}">
+						<div class="callout location" data-name="{{ .Name }}" data-lat="{{ .Coordinates.Lat }}" data-lng="{{ .Coordinates.Lng }}"{{ if .Geocode.LowConfidence }} data-approximate="true"{{ end }}>
 							{{ .Name }}
+							{{ with .Parts }}
+								<br>
+								<small>
+									{{ if .Address }}{{ .Address }}{{ end }}
+									{{ if index .Intersection 0 }}{{ index .Intersection 0 }} &amp; {{ index .Intersection 1 }}{{ end }}
+									{{ if .Range.Street }}{{ .Range.Street }} from {{ .Range.From }} to {{ .Range.To }}{{ end }}
+									{{ if .Neighborhood }}&middot; {{ .Neighborhood }}{{ end }}
+								</small>
+							{{ end }}
+							{{ with .Geocode }}
+								{{ if .FormattedAddress }}
+									<br>
+									<small title="Matched query: {{ .Query }}">
+										Geocoded as {{ .FormattedAddress }}{{ if .LowConfidence }} (approximate){{ end }}
+									</small>
+								{{ end }}
+							{{ end }}
 							{{ if .FunFact }}
 								<hr>
 								<em>
@@ -32,18 +49,25 @@
 		</div>
 	</div>
 	<div class="tabs-panel" id="tab-info">
+		{{ $info := .Info }}
 		<div class="row">
 			<div class="row">
 				<div class="medium-4 columns">
 				</div>
 				<div class="medium-8 columns">
-					<h3>Details (from <a href="https://www.omdbapi.com/">OMDB</a>)</h3>
+					<h3>Details{{ with $info.Provider }} (from {{ . }}){{ end }}</h3>
+					{{ $now := .Now }}
+					{{ with .Cached }}
+						{{ if not .FetchedAt.IsZero }}
+							<p><small>
+								Fetched {{ age .FetchedAt $now }} ago{{ if .Expired $now }} (expired, to be refreshed){{ else }}, expires in {{ age $now .ExpiresAt }}{{ end }}.
+							</small></p>
+						{{ end }}
+					{{ end }}
 				</div>
 			</div>
 			<div class="medium-4 columns">
-				{{ $info := .Info }}
-				{{ $poster := $info.Poster }}
-				{{ if ne $poster "N/A" }}<img src="{{ $poster }}"/>{{ end }}
+				{{ if $info.PosterUrl }}<img src="/poster/{{ .MovieId }}?size=large&v={{ .PosterVersion }}"/>{{ end }}
 			</div>
 			<div class="medium-8 columns">
 				<table>
@@ -53,19 +77,32 @@
 					</tr>
 					<tr>
 						<td>IMDB ID</td>
-						<td>{{ if $info.ImdbID }} <a href="http://www.imdb.com/title/{{ $info.ImdbID }}">{{ $info.ImdbID }}</a> {{ else }} <i>N/A</i> {{ end }}</td>
+						<td>{{ if $info.ImdbId }} <a href="http://www.imdb.com/title/{{ $info.ImdbId }}">{{ $info.ImdbId }}</a> {{ else }} <i>N/A</i> {{ end }}</td>
+					</tr>
+					<tr>
+						<td>Match</td>
+						<td>
+							{{ if .Pinned }}
+								Pinned to IMDb ID
+							{{ else if $info.MatchConfidence }}
+								{{ percent $info.MatchConfidence }} confidence{{ if lt $info.MatchConfidence 0.75 }} (may be the wrong movie){{ end }}
+							{{ else }}
+								<i>N/A</i>
+							{{ end }}
+							<small>(<a href="/admin/movie-pins?movie={{ .MovieId }}">pin</a>)</small>
+						</td>
 					</tr>
 					<tr>
 						<td>IMDB Rating</td>
-						<td>{{ field $info.ImdbRating }} ({{ field $info.ImdbVotes }} votes)</td>
+						<td>{{ number $info.ImdbRating }} ({{ number $info.ImdbVotes }} votes)</td>
 					</tr>
 					<tr>
 						<td>Metascore</td>
-						<td>{{ field $info.Metascore }}</td>
+						<td>{{ number $info.Metascore }}</td>
 					</tr>
 					<tr>
 						<td>Genre</td>
-						<td>{{ field $info.Genre }}</td>
+						<td>{{ list $info.Genres }}</td>
 					</tr>
 					<tr>
 						<td>Plot</td>
@@ -73,23 +110,23 @@
 					</tr>
 					<tr>
 						<td>Writer</td>
-						<td>{{ field $info.Writer }}</td>
+						<td>{{ list .Credits.Writers.Values }} {{ template "provenance" .Credits.Writers }}</td>
 					</tr>
 					<tr>
 						<td>Director</td>
-						<td>{{ field $info.Director }}</td>
+						<td>{{ list .Credits.Director.Values }} {{ template "provenance" .Credits.Director }}</td>
 					</tr>
 					<tr>
 						<td>Actors</td>
-						<td>{{ field $info.Actors }}</td>
+						<td>{{ list .Credits.Actors.Values }} {{ template "provenance" .Credits.Actors }}</td>
 					</tr>
 					<tr>
 						<td>Language</td>
-						<td>{{ field $info.Language }}</td>
+						<td>{{ list $info.Languages }}</td>
 					</tr>
 					<tr>
 						<td>Country</td>
-						<td>{{ field $info.Country }}</td>
+						<td>{{ list $info.Countries }}</td>
 					</tr>
 					<tr>
 						<td>Awards</td>
@@ -97,7 +134,7 @@
 					</tr>
 					<tr>
 						<td>Year</td>
-						<td>{{ field $info.Year }}</td>
+						<td>{{ list .Credits.Year.Values }} {{ template "provenance" .Credits.Year }}</td>
 					</tr>
 					<tr>
 						<td>Released</td>
@@ -105,7 +142,7 @@
 					</tr>
 					<tr>
 						<td>Runtime</td>
-						<td>{{ field $info.Runtime }}</td>
+						<td>{{ if $info.RuntimeMinutes }}{{ $info.RuntimeMinutes }} min{{ else }}<i>N/A</i>{{ end }}</td>
 					</tr>
 					<tr>
 						<td>Rated</td>
@@ -121,3 +158,13 @@
 <script async defer src="https://maps.googleapis.com/maps/api/js?key={{ maps_api_key }}&callback=initMap"></script>
 
 {{ end }}
+
+{{ define "provenance" }}
+	{{ with .Source }}<small>(from {{ source . }})</small>{{ end }}
+	{{ if .Discrepancy }}
+		<br>
+		<small title="DataSF: {{ join .DataSf }}; provider: {{ join .Metadata }}">
+			{{ if .Suspicious }}<b>Suspicious:</b> {{ end }}{{ .Discrepancy }}
+		</small>
+	{{ end }}
+{{ end }}
@@ -0,0 +1,53 @@
+{{ define "content" }}
+
+<h1>Movie pins</h1>
+
+<p>
+	Movies whose info is matched wrongly (like remakes or movies with common titles) can be pinned to their IMDb ID.
+	The info of pinned movies is fetched by that ID instead of searching for the best match. Changing a pin fetches the
+	info of the movie right away.
+</p>
+
+<h2>Pin movie</h2>
+<form action="/admin/movie-pins" method="post">
+	<div class="row">
+		<div class="medium-3 columns"><input type="text" name="movie" placeholder="Movie ID" value="{{ .MovieId }}" required></div>
+		<div class="medium-3 columns"><input type="text" name="imdb_id" placeholder="IMDb ID (like tt0052357)" pattern="tt[0-9]+" required></div>
+		<div class="medium-6 columns"><button class="button small" name="action" value="pin">Pin</button></div>
+	</div>
+</form>
+
+<h2>Pins</h2>
+{{ if .Pins }}
+	<table>
+		<tr>
+			<th>Movie</th>
+			<th>Director</th>
+			<th>IMDb ID</th>
+			<th>Updated by</th>
+			<th>Updated at</th>
+			<th></th>
+		</tr>
+		{{ range .Pins }}
+			<tr>
+				<td>{{ .Key.Title }}{{ if .Key.ReleaseYear }} ({{ .Key.ReleaseYear }}){{ end }}</td>
+				<td>{{ .Key.Director }}</td>
+				<td><a href="http://www.imdb.com/title/{{ .ImdbId }}">{{ .ImdbId }}</a></td>
+				<td>{{ .UpdatedBy }}</td>
+				<td>{{ .UpdatedAt }}</td>
+				<td>
+					<form action="/admin/movie-pins" method="post">
+						<input type="hidden" name="title" value="{{ .Key.Title }}">
+						<input type="hidden" name="release_year" value="{{ .Key.ReleaseYear }}">
+						<input type="hidden" name="director" value="{{ .Key.Director }}">
+						<button class="button tiny alert" name="action" value="unpin">Unpin</button>
+					</form>
+				</td>
+			</tr>
+		{{ end }}
+	</table>
+{{ else }}
+	<p>No movies are pinned.</p>
+{{ end }}
+
+{{ end }}
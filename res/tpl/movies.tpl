@@ -8,14 +8,29 @@
 		(NOTE: Not yet concurrency-safe because requests hit multiple instances)
 	</p>
 </form>
+<form action="/movie" method="get">
+	<input type="text" name="genre" placeholder="Genre" value="{{ .Filter.Genre }}">
+	<input type="text" name="language" placeholder="Language" value="{{ .Filter.Language }}">
+	<input type="text" name="country" placeholder="Country" value="{{ .Filter.Country }}">
+	<input type="number" name="min_rating" placeholder="Min. IMDb rating" step="0.1" min="0" max="10" value="{{ if .Filter.MinImdbRating }}{{ .Filter.MinImdbRating }}{{ end }}">
+	<input type="number" name="max_runtime" placeholder="Max. runtime (min)" min="0" value="{{ if .Filter.MaxRuntimeMinutes }}{{ .Filter.MaxRuntimeMinutes }}{{ end }}">
+	<button class="button">Filter</button>
+</form>
 <ul>
-	{{ range . }}
+	{{ range .Movies }}
 		<li>
 			{{ $m := .Movie}}
 			<a href="/movie/{{.Id}}">{{ if $m.Title }}<b>{{ $m.Title }}</b>{{ else }}<i>[No title]</i>{{ end }}</a>
+			{{ if $m.ReleaseYear }}({{ $m.ReleaseYear }}){{ end }}
 			{{ if $m.Writer}}<i>Written by </i> {{ $m.Writer }}.{{end}}
 			{{ $actors := join $m.Actors }}
 			{{ if $actors }}<i>Actor(s):</i> {{ $actors }}.{{ end }}
+			{{ with .Metadata }}
+				{{ $genres := join .Genres }}
+				{{ if $genres }}<i>Genre(s):</i> {{ $genres }}.{{ end }}
+				{{ if .ImdbRating }}<i>IMDb rating:</i> {{ .ImdbRating }}.{{ end }}
+				{{ if .RuntimeMinutes }}<i>Runtime:</i> {{ .RuntimeMinutes }} min.{{ end }}
+			{{ end }}
 			<ul>
 				{{ range $m.Locations }}
 					<li>
@@ -25,6 +40,8 @@
 				{{ end }}
 			</ul>
 		</li>
+	{{ else }}
+		<li><i>No movies match the filter.</i></li>
 	{{ end }}
 </ul>
 
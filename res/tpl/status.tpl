@@ -47,11 +47,163 @@
 	</tr>
 </table>
 
+<h2>Admin</h2>
+<ul>
+	<li><a href="/admin/merges">Review location name merges</a></li>
+	<li><a href="/admin/geocode-failures">Clear failed geocoding lookups</a></li>
+	<li><a href="/admin/geocode-reviews">Review ambiguous geocodes</a></li>
+	<li><a href="/admin/coordinates">Override coordinates</a></li>
+	<li><a href="/admin/movie-pins">Pin IMDb IDs of movies</a></li>
+	<li><a href="/admin/credit-discrepancies">Review credit discrepancies</a></li>
+	<li><a href="/admin/seed">Export seed of movie info and coordinates</a></li>
+</ul>
+<form action="/admin/seed" method="post" enctype="multipart/form-data">
+	<input type="file" name="seed" accept=".json,application/json" required>
+	<button class="button small">Import seed</button>
+</form>
+
 <h2>Init/update</h2>
 Note that this information only concerns the particular application instance that you happened to hit with this request.
 
 <h3>Error</h3>
 <pre>{{ .RecordedErr }}</pre>
+<h3>Validation</h3>
+{{ with .RecordedReport }}
+	<p>
+		Validated {{ len .Rows }} rows from <code>{{ .Source }}</code> at {{ .Time }}
+		(<a href="/status/report.json">download as JSON</a>).
+	</p>
+	<table>
+		<tr><td>Accepted</td><td>{{ .Accepted }}</td></tr>
+		<tr><td>Repaired</td><td>{{ .Repaired }}</td></tr>
+		<tr><td>Rejected</td><td>{{ .Rejected }}</td></tr>
+		<tr><td>With warnings</td><td>{{ .Warned }}</td></tr>
+	</table>
+	{{ $rejected := .RowsWithStatus "rejected" }}
+	{{ if $rejected }}
+		<h4>Rejected rows</h4>
+		<table>
+			<tr>
+				<th>Row</th>
+				<th>Title</th>
+				<th>Location</th>
+				<th>Reasons</th>
+			</tr>
+			{{ range $rejected }}
+				<tr>
+					<td>{{ .Row }}</td>
+					<td>{{ .Title }}</td>
+					<td>{{ .Location }}</td>
+					<td>{{ range .Reasons }}{{ . }}<br>{{ end }}</td>
+				</tr>
+			{{ end }}
+		</table>
+	{{ end }}
+	{{ $warned := .RowsWithWarnings }}
+	{{ if $warned }}
+		<h4>Rows with warnings</h4>
+		<table>
+			<tr>
+				<th>Row</th>
+				<th>Title</th>
+				<th>Location</th>
+				<th>Warnings</th>
+			</tr>
+			{{ range $warned }}
+				<tr>
+					<td>{{ .Row }}</td>
+					<td>{{ .Title }}</td>
+					<td>{{ .Location }}</td>
+					<td>{{ range .Warnings }}{{ . }}<br>{{ end }}</td>
+				</tr>
+			{{ end }}
+		</table>
+	{{ end }}
+	{{ if .Conflicts }}
+		<h4>Conflicting rows</h4>
+		<table>
+			<tr>
+				<th>Title</th>
+				<th>Reason</th>
+				<th>Rows</th>
+			</tr>
+			{{ range .Conflicts }}
+				<tr>
+					<td>{{ .Title }}</td>
+					<td>{{ .Reason }}</td>
+					<td>{{ range .Rows }}{{ . }} {{ end }}</td>
+				</tr>
+			{{ end }}
+		</table>
+	{{ end }}
+{{ else }}
+	<p>No validation report has been recorded.</p>
+{{ end }}
+<h3>Geocoding job</h3>
+{{ with .GeocodeJob }}
+	<p>
+		{{ if .Finished }}
+			Finished at {{ .FinishedAt }}.
+		{{ else }}
+			In progress (last checkpoint at {{ .UpdatedAt }}, after <i>{{ .Cursor }}</i>).
+		{{ end }}
+		Started at {{ .StartedAt }}.
+	</p>
+	<table>
+		<tr><td>Location names</td><td>{{ .Total }}</td></tr>
+		<tr><td>Processed</td><td>{{ .Processed }}</td></tr>
+		<tr><td>Resolved</td><td>{{ .Resolved }}</td></tr>
+		<tr><td>Failed</td><td>{{ .Failed }}</td></tr>
+	</table>
+{{ else }}
+	<p>The geocoding job has not been started.</p>
+{{ end }}
+<h3>Outbound requests</h3>
+{{ if .HostStatuses }}
+	<table>
+		<tr>
+			<th>Host</th>
+			<th>Circuit breaker</th>
+			<th>Requests</th>
+			<th>Retries</th>
+			<th>Failures</th>
+			<th>Rejected</th>
+		</tr>
+		{{ range .HostStatuses }}
+			<tr>
+				<td>{{ .Host }}</td>
+				<td>{{ .State }}{{ if ne .State "closed" }} (since {{ .OpenedAt }}){{ end }}</td>
+				<td>{{ .Requests }}</td>
+				<td>{{ .Retries }}</td>
+				<td>{{ .Failures }}</td>
+				<td>{{ .Rejected }}</td>
+			</tr>
+		{{ end }}
+	</table>
+{{ else }}
+	<p>No outbound requests have been made by this instance.</p>
+{{ end }}
+<h4>Rate limits</h4>
+<table>
+	<tr>
+		<th>API</th>
+		<th>Requests per second</th>
+		<th>Burst</th>
+		<th>Throttled requests</th>
+		<th>Total wait (ms)</th>
+		<th>Quota pauses</th>
+	</tr>
+	{{ range .RateLimiters }}
+		<tr>
+			<td>{{ .Api }}</td>
+			<td>{{ .PerSecond }}</td>
+			<td>{{ .Burst }}</td>
+			<td>{{ .Waits }}</td>
+			<td>{{ .WaitedMillis }}</td>
+			<td>{{ .Pauses }}{{ if .Pauses }} (last until {{ .PausedUntil }}){{ end }}</td>
+		</tr>
+	{{ end }}
+</table>
 <h3>Log</h3>
 <ul>
 	{{ range .RecordedLog }}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"src/data/fetch"
+	"src/data/types"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const BasePath = "/api/v1"
+
+// Resources of the API built from the movies of the database (with the coordinates of their locations). Movies are
+// identified by slugs of their identity and locations and people by slugs of their names, such that the IDs don't change
+// with updates.
+type Catalog struct {
+	movies        []Movie
+	movieIndex    map[string]int
+	movieDbIds    []int64
+	movieFeatures [][]Feature
+	locations     []Location
+	locationIndex map[string]int
+	people        []Person
+	personIndex   map[string]int
+}
+
+var nonSlugRegex = regexp.MustCompile("[^a-z0-9]+")
+
+// Identifier of a name in URLs (like "golden-gate-bridge" for "Golden Gate Bridge").
+func Slug(name string) string {
+	return strings.Trim(nonSlugRegex.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// Identifier of a movie in URLs (like "vertigo-1958-alfred-hitchcock"). Unlike the ID in the database, it stays the same
+// across updates.
+func MovieId(key types.MovieKey) string {
+	name := key.Title
+	if key.ReleaseYear != 0 {
+		name += fmt.Sprintf(" %d", key.ReleaseYear)
+	}
+	return Slug(name + " " + key.Director)
+}
+
+// Short hash of the identity of a movie, which tells apart movies whose IDs would be the same (as their identities only
+// differ in punctuation).
+func movieKeyHash(key types.MovieKey) string {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s\x00%d\x00%s", key.Title, key.ReleaseYear, key.Director)
+	return strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+func movieLinks(id string) Links {
+	return Links{Self: BasePath + "/movies/" + id}
+}
+
+func locationLinks(id string) Links {
+	return Links{Self: BasePath + "/locations/" + id}
+}
+
+func personLinks(id string) Links {
+	return Links{Self: BasePath + "/people/" + id}
+}
+
+func coordinates(loc types.Location) *Coordinates {
+	if loc.Coordinates.Lat == 0 && loc.Coordinates.Lng == 0 {
+		return nil
+	}
+	return &Coordinates{Lat: loc.Coordinates.Lat, Lng: loc.Coordinates.Lng, Approximate: loc.Geocode.LowConfidence()}
+}
+
+// Build the catalog from movies (sorted by title) and the canonical names of merged location names.
+func NewCatalog(movies []types.IdMoviePair, aliases map[string]string) *Catalog {
+	c := &Catalog{movieIndex: make(map[string]int)}
+	locations := make(map[string]*Location)
+	people := make(map[string]*Person)
+	
+	for _, p := range movies {
+		mv := p.Movie
+		movieId := MovieId(mv.Key())
+		if _, exists := c.movieIndex[movieId]; exists || movieId == "" {
+			// Movies whose identities only differ in punctuation would share the ID.
+			movieId = strings.TrimPrefix(movieId + "-" + movieKeyHash(mv.Key()), "-")
+		}
+		ref := MovieRef{Id: movieId, Title: mv.Title, ReleaseYear: mv.ReleaseYear, Links: movieLinks(movieId)}
+		m := Movie{
+			Id:                movieId,
+			Title:             mv.Title,
+			ReleaseYear:       mv.ReleaseYear,
+			Director:          mv.Director,
+			Writer:            mv.Writer,
+			Distributor:       mv.Distributor,
+			ProductionCompany: mv.ProductionCompany,
+			People:            []PersonRef{},
+			Locations:         []MovieLocation{},
+			Links:             ref.Links,
+		}
+		
+		credits := []struct {
+			role  string
+			names []string
+		}{
+			{RoleDirector, fetch.SplitCredits(mv.Director)},
+			{RoleWriter, fetch.SplitCredits(mv.Writer)},
+			{RoleActor, mv.Actors},
+		}
+		for _, credit := range credits {
+			for _, name := range credit.names {
+				id := Slug(name)
+				if id == "" {
+					continue
+				}
+				m.People = append(m.People, PersonRef{Id: id, Name: name, Role: credit.role, Links: personLinks(id)})
+				
+				person, exists := people[id]
+				if !exists {
+					person = &Person{Id: id, Name: name, Links: personLinks(id)}
+					people[id] = person
+				}
+				person.Roles = addString(person.Roles, credit.role)
+				if n := len(person.Movies); n > 0 && person.Movies[n - 1].Id == movieId {
+					person.Movies[n - 1].Roles = addString(person.Movies[n - 1].Roles, credit.role)
+				} else {
+					person.Movies = append(person.Movies, PersonMovie{MovieRef: ref, Roles: []string{credit.role}})
+				}
+			}
+		}
+		
+		var features []Feature
+		for _, loc := range mv.Locations {
+			canonical := loc.Name
+			if name, exists := aliases[loc.Name]; exists {
+				canonical = name
+			}
+			id := Slug(canonical)
+			if id == "" {
+				continue
+			}
+			coords := coordinates(loc)
+			m.Locations = append(m.Locations, MovieLocation{
+				Id:          id,
+				Name:        loc.Name,
+				FunFact:     loc.FunFact,
+				Coordinates: coords,
+				Links:       locationLinks(id),
+			})
+			if f := newFeature(movieId, mv, id, loc); f != nil {
+				features = append(features, *f)
+			}
+			
+			location, exists := locations[id]
+			if !exists {
+				location = &Location{Id: id, Name: canonical, Coordinates: coords, Links: locationLinks(id)}
+				locations[id] = location
+			}
+			if n := len(location.Movies); n == 0 || location.Movies[n - 1].Id != movieId {
+				location.Movies = append(location.Movies, ref)
+			}
+		}
+		
+		c.movieIndex[movieId] = len(c.movies)
+		c.movies = append(c.movies, m)
+		c.movieDbIds = append(c.movieDbIds, p.Id)
+		c.movieFeatures = append(c.movieFeatures, features)
+	}
+	
+	c.locationIndex = make(map[string]int)
+	for _, l := range locations {
+		c.locations = append(c.locations, *l)
+	}
+	sort.Sort(byLocationId(c.locations))
+	for i, l := range c.locations {
+		c.locationIndex[l.Id] = i
+	}
+	
+	c.personIndex = make(map[string]int)
+	for _, p := range people {
+		c.people = append(c.people, *p)
+	}
+	sort.Sort(byPersonId(c.people))
+	for i, p := range c.people {
+		c.personIndex[p.Id] = i
+	}
+	return c
+}
+
+// ID in the database of the movie with the given ID of the catalog (which is only valid until the next update).
+func (c *Catalog) MovieDbId(id string) (int64, bool) {
+	i, exists := c.movieIndex[id]
+	if !exists {
+		return 0, false
+	}
+	return c.movieDbIds[i], true
+}
+
+func addString(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+type byLocationId []Location
+
+func (ls byLocationId) Len() int {
+	return len(ls)
+}
+func (ls byLocationId) Swap(i, j int) {
+	ls[i], ls[j] = ls[j], ls[i]
+}
+func (ls byLocationId) Less(i, j int) bool {
+	return ls[i].Id < ls[j].Id
+}
+
+type byPersonId []Person
+
+func (ps byPersonId) Len() int {
+	return len(ps)
+}
+func (ps byPersonId) Swap(i, j int) {
+	ps[i], ps[j] = ps[j], ps[i]
+}
+func (ps byPersonId) Less(i, j int) bool {
+	return ps[i].Id < ps[j].Id
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"src/data/types"
+)
+
+const geoJsonContentType = "application/geo+json"
+
+// Collection of features as defined by GeoJSON (RFC 7946).
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature of a location of a movie. Locations without coordinates are left out as not all GIS tools handle features
+// without geometry.
+type Feature struct {
+	Type       string            `json:"type"`
+	Geometry   Point             `json:"geometry"`
+	Properties FeatureProperties `json:"properties"`
+}
+
+// Point with its coordinates in the order longitude, latitude (as opposed to the rest of the API).
+type Point struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float32 `json:"coordinates"`
+}
+
+// Properties of the feature of a location of a movie. The quality of the geocode is given by the geocoder that found the
+// coordinates (or "override" if they were set by an admin), their precision (like "ROOFTOP" or "APPROXIMATE"), and
+// whether they only approximate the location.
+type FeatureProperties struct {
+	MovieId          string `json:"movie_id"`
+	MovieTitle       string `json:"movie_title"`
+	ReleaseYear      int    `json:"release_year"`
+	LocationId       string `json:"location_id"`
+	LocationName     string `json:"location_name"`
+	FunFact          string `json:"fun_fact"`
+	GeocodeSource    string `json:"geocode_source"`
+	GeocodePrecision string `json:"geocode_precision"`
+	Approximate      bool   `json:"approximate"`
+}
+
+func newFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// Feature of a location of a movie (or nil if it has no coordinates).
+func newFeature(movieId string, movie types.Movie, locationId string, loc types.Location) *Feature {
+	c := coordinates(loc)
+	if c == nil {
+		return nil
+	}
+	return &Feature{
+		Type:     "Feature",
+		Geometry: Point{Type: "Point", Coordinates: [2]float32{c.Lng, c.Lat}},
+		Properties: FeatureProperties{
+			MovieId:          movieId,
+			MovieTitle:       movie.Title,
+			ReleaseYear:      movie.ReleaseYear,
+			LocationId:       locationId,
+			LocationName:     loc.Name,
+			FunFact:          loc.FunFact,
+			GeocodeSource:    loc.Geocode.Source,
+			GeocodePrecision: loc.Geocode.LocationType,
+			Approximate:      c.Approximate,
+		},
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+// Serve a request of a path below `BasePath` from the catalog. Lists are paginated by the query parameters "offset" and
+// "limit". The locations of a movie and of all movies are also served as GeoJSON (unpaginated).
+func Serve(w http.ResponseWriter, r *http.Request, c *Catalog) {
+	if r.Method != "GET" {
+		WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed", fmt.Sprintf("Cannot %s '%s'", r.Method, r.URL.Path))
+		return
+	}
+	
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, BasePath), "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "movies":
+		writeList(w, r, len(c.movies), func (offset, end int) interface{} { return c.movies[offset:end] })
+	case len(parts) == 2 && parts[0] == "movies":
+		i, exists := c.movieIndex[parts[1]]
+		if !exists {
+			WriteError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Movie '%s' not found", parts[1]))
+			return
+		}
+		writeJson(w, http.StatusOK, c.movies[i])
+	case len(parts) == 3 && parts[0] == "movies" && parts[2] == "locations.geojson":
+		i, exists := c.movieIndex[parts[1]]
+		if !exists {
+			WriteError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Movie '%s' not found", parts[1]))
+			return
+		}
+		writeJsonAs(w, http.StatusOK, geoJsonContentType, newFeatureCollection(c.movieFeatures[i]))
+	case len(parts) == 1 && parts[0] == "locations.geojson":
+		var features []Feature
+		for _, fs := range c.movieFeatures {
+			features = append(features, fs...)
+		}
+		writeJsonAs(w, http.StatusOK, geoJsonContentType, newFeatureCollection(features))
+	case len(parts) == 1 && parts[0] == "locations":
+		writeList(w, r, len(c.locations), func (offset, end int) interface{} { return c.locations[offset:end] })
+	case len(parts) == 2 && parts[0] == "locations":
+		i, exists := c.locationIndex[parts[1]]
+		if !exists {
+			WriteError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Location '%s' not found", parts[1]))
+			return
+		}
+		writeJson(w, http.StatusOK, c.locations[i])
+	case len(parts) == 1 && parts[0] == "people":
+		writeList(w, r, len(c.people), func (offset, end int) interface{} { return c.people[offset:end] })
+	case len(parts) == 2 && parts[0] == "people":
+		i, exists := c.personIndex[parts[1]]
+		if !exists {
+			WriteError(w, http.StatusNotFound, "not_found", fmt.Sprintf("Person '%s' not found", parts[1]))
+			return
+		}
+		writeJson(w, http.StatusOK, c.people[i])
+	default:
+		WriteError(w, http.StatusNotFound, "not_found", fmt.Sprintf("No resource at '%s'", r.URL.Path))
+	}
+}
+
+// Write the page of a list given by the pagination parameters of the request.
+func writeList(w http.ResponseWriter, r *http.Request, total int, page func (offset, end int) interface{}) {
+	offset, limit, err := pagination(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+	
+	start := offset
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+	
+	links := Links{Self: pageUrl(r, offset, limit), First: pageUrl(r, 0, limit)}
+	if total > 0 {
+		links.Last = pageUrl(r, (total - 1) / limit * limit, limit)
+	}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		links.Prev = pageUrl(r, prev, limit)
+	}
+	if end < total {
+		links.Next = pageUrl(r, end, limit)
+	}
+	writeJson(w, http.StatusOK, List{Data: page(start, end), Total: total, Offset: offset, Limit: limit, Links: links})
+}
+
+func pagination(r *http.Request) (int, int, error) {
+	offset := 0
+	limit := defaultLimit
+	if s := r.FormValue("offset"); s != "" {
+		o, err := strconv.Atoi(s)
+		if err != nil || o < 0 {
+			return 0, 0, fmt.Errorf("Invalid offset '%s'", s)
+		}
+		offset = o
+	}
+	if s := r.FormValue("limit"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l < 1 || l > maxLimit {
+			return 0, 0, fmt.Errorf("Invalid limit '%s' (must be between 1 and %d)", s, maxLimit)
+		}
+		limit = l
+	}
+	return offset, limit, nil
+}
+
+// URL of the request with the given pagination parameters (keeping the other parameters).
+func pageUrl(r *http.Request, offset int, limit int) string {
+	params := url.Values{}
+	for k, v := range r.URL.Query() {
+		params[k] = v
+	}
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("limit", strconv.Itoa(limit))
+	return r.URL.Path + "?" + params.Encode()
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	writeJsonAs(w, status, "application/json; charset=utf-8", v)
+}
+
+func writeJsonAs(w http.ResponseWriter, status int, contentType string, v interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Write an error object with the HTTP status, a machine-readable code (like "not_found"), and a message.
+func WriteError(w http.ResponseWriter, status int, code string, message string) {
+	writeJson(w, status, errorResponse{Error: Error{Status: status, Code: code, Message: message}})
+}
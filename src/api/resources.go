@@ -0,0 +1,105 @@
+package api
+
+// Resources of the API. Their JSON field names are part of the API and must not change within a version, which is why
+// they are kept apart from the internal types.
+
+type Links struct {
+	Self  string `json:"self"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// Coordinates of a location. `Approximate` is set if the geocoder could only resolve the location roughly (like to its
+// neighborhood).
+type Coordinates struct {
+	Lat         float32 `json:"lat"`
+	Lng         float32 `json:"lng"`
+	Approximate bool    `json:"approximate"`
+}
+
+type Movie struct {
+	Id                string          `json:"id"`
+	Title             string          `json:"title"`
+	ReleaseYear       int             `json:"release_year"`
+	Director          string          `json:"director"`
+	Writer            string          `json:"writer"`
+	Distributor       string          `json:"distributor"`
+	ProductionCompany string          `json:"production_company"`
+	People            []PersonRef     `json:"people"`
+	Locations         []MovieLocation `json:"locations"`
+	Links             Links           `json:"links"`
+}
+
+// Location of a movie under its name in the data set. Coordinates are null if they haven't been found (yet).
+type MovieLocation struct {
+	Id          string       `json:"id"`
+	Name        string       `json:"name"`
+	FunFact     string       `json:"fun_fact"`
+	Coordinates *Coordinates `json:"coordinates"`
+	Links       Links        `json:"links"`
+}
+
+type MovieRef struct {
+	Id          string `json:"id"`
+	Title       string `json:"title"`
+	ReleaseYear int    `json:"release_year"`
+	Links       Links  `json:"links"`
+}
+
+// Location with the movies shot there. Variants of a location name that have been merged are one location.
+type Location struct {
+	Id          string       `json:"id"`
+	Name        string       `json:"name"`
+	Coordinates *Coordinates `json:"coordinates"`
+	Movies      []MovieRef   `json:"movies"`
+	Links       Links        `json:"links"`
+}
+
+// Roles of people.
+const (
+	RoleActor    = "actor"
+	RoleDirector = "director"
+	RoleWriter   = "writer"
+)
+
+type PersonRef struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+	Links Links  `json:"links"`
+}
+
+type PersonMovie struct {
+	MovieRef
+	Roles []string `json:"roles"`
+}
+
+// Actor, director, or writer credited by the data set.
+type Person struct {
+	Id     string        `json:"id"`
+	Name   string        `json:"name"`
+	Roles  []string      `json:"roles"`
+	Movies []PersonMovie `json:"movies"`
+	Links  Links         `json:"links"`
+}
+
+// Page of a list of resources.
+type List struct {
+	Data   interface{} `json:"data"`
+	Total  int         `json:"total"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+	Links  Links       `json:"links"`
+}
+
+type Error struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type errorResponse struct {
+	Error Error `json:"error"`
+}
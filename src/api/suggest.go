@@ -0,0 +1,211 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const SuggestPath = "/api/suggest"
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+// Types of suggestions.
+const (
+	SuggestionMovie    = "movie"
+	SuggestionPerson   = "person"
+	SuggestionLocation = "location"
+)
+
+// Suggestion of a movie, person, or location for autocompletion. The ID is the one of the resource of the type (like
+// "vertigo-1958-alfred-hitchcock" for a movie or "golden-gate-bridge" for a location).
+type Suggestion struct {
+	Id    string `json:"id"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+}
+
+type suggestEntry struct {
+	Suggestion
+	words  []string
+	phrase string
+}
+
+type indexedWord struct {
+	word  string
+	entry int
+}
+
+// Index of the labels of movies, people, and locations by their words for finding suggestions with words starting with
+// the ones of a query.
+type SuggestIndex struct {
+	entries []suggestEntry
+	words   []indexedWord
+}
+
+// Lowercase words of a label or query (ignoring punctuation).
+func suggestWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func (r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Build the suggestion index from the movies (labeled with their release year), people, and locations of a catalog.
+func NewSuggestIndex(c *Catalog) *SuggestIndex {
+	idx := &SuggestIndex{}
+	for _, m := range c.movies {
+		label := m.Title
+		if m.ReleaseYear != 0 {
+			// Tell apart movies with the same title.
+			label = fmt.Sprintf("%s (%d)", m.Title, m.ReleaseYear)
+		}
+		idx.add(Suggestion{Id: m.Id, Label: label, Type: SuggestionMovie}, m.Title)
+	}
+	for _, p := range c.people {
+		idx.add(Suggestion{Id: p.Id, Label: p.Name, Type: SuggestionPerson}, p.Name)
+	}
+	for _, l := range c.locations {
+		idx.add(Suggestion{Id: l.Id, Label: l.Name, Type: SuggestionLocation}, l.Name)
+	}
+	sort.Sort(byIndexedWord(idx.words))
+	return idx
+}
+
+func (idx *SuggestIndex) add(s Suggestion, name string) {
+	words := suggestWords(name)
+	if len(words) == 0 {
+		return
+	}
+	for _, w := range words {
+		idx.words = append(idx.words, indexedWord{word: w, entry: len(idx.entries)})
+	}
+	idx.entries = append(idx.entries, suggestEntry{Suggestion: s, words: words, phrase: strings.Join(words, " ")})
+}
+
+// Ranks of matches (lower is better).
+const (
+	rankPrefix       = iota // The name starts with the query.
+	rankWordBoundary        // The name contains the query starting at a word.
+	rankWords               // Every word of the query starts a word of the name.
+)
+
+type rankedSuggestion struct {
+	entry *suggestEntry
+	rank  int
+}
+
+// Suggestions (at most `limit`) whose name matches the query, ranked by how well they match and then by length of the
+// name (as shorter names match more closely).
+func (idx *SuggestIndex) Suggest(query string, limit int) []Suggestion {
+	suggestions := []Suggestion{}
+	qWords := suggestWords(query)
+	if len(qWords) == 0 {
+		return suggestions
+	}
+	qPhrase := strings.Join(qWords, " ")
+	
+	// Candidates have a word starting with the first word of the query.
+	first := qWords[0]
+	i := sort.Search(len(idx.words), func (i int) bool { return idx.words[i].word >= first })
+	seen := make(map[int]bool)
+	var matches []rankedSuggestion
+	for ; i < len(idx.words) && strings.HasPrefix(idx.words[i].word, first); i++ {
+		n := idx.words[i].entry
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		e := &idx.entries[n]
+		if rank, ok := matchRank(e, qWords, qPhrase); ok {
+			matches = append(matches, rankedSuggestion{e, rank})
+		}
+	}
+	
+	sort.Sort(byRank(matches))
+	for _, m := range matches {
+		if len(suggestions) == limit {
+			break
+		}
+		suggestions = append(suggestions, m.entry.Suggestion)
+	}
+	return suggestions
+}
+
+func matchRank(e *suggestEntry, qWords []string, qPhrase string) (int, bool) {
+	if strings.HasPrefix(e.phrase, qPhrase) {
+		return rankPrefix, true
+	}
+	for i := 1; i < len(e.words); i++ {
+		if strings.HasPrefix(strings.Join(e.words[i:], " "), qPhrase) {
+			return rankWordBoundary, true
+		}
+	}
+	for _, q := range qWords {
+		matched := false
+		for _, w := range e.words {
+			if strings.HasPrefix(w, q) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return 0, false
+		}
+	}
+	return rankWords, true
+}
+
+// Serve the suggestions for the query parameter "q" (at most "limit").
+func ServeSuggest(w http.ResponseWriter, r *http.Request, idx *SuggestIndex) {
+	if r.Method != "GET" {
+		WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed", fmt.Sprintf("Cannot %s '%s'", r.Method, r.URL.Path))
+		return
+	}
+	
+	limit := defaultSuggestLimit
+	if s := r.FormValue("limit"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l < 1 || l > maxSuggestLimit {
+			WriteError(w, http.StatusBadRequest, "invalid_parameter", fmt.Sprintf("Invalid limit '%s' (must be between 1 and %d)", s, maxSuggestLimit))
+			return
+		}
+		limit = l
+	}
+	writeJson(w, http.StatusOK, idx.Suggest(r.FormValue("q"), limit))
+}
+
+type byIndexedWord []indexedWord
+
+func (ws byIndexedWord) Len() int {
+	return len(ws)
+}
+func (ws byIndexedWord) Swap(i, j int) {
+	ws[i], ws[j] = ws[j], ws[i]
+}
+func (ws byIndexedWord) Less(i, j int) bool {
+	return ws[i].word < ws[j].word
+}
+
+type byRank []rankedSuggestion
+
+func (ss byRank) Len() int {
+	return len(ss)
+}
+func (ss byRank) Swap(i, j int) {
+	ss[i], ss[j] = ss[j], ss[i]
+}
+func (ss byRank) Less(i, j int) bool {
+	if ss[i].rank != ss[j].rank {
+		return ss[i].rank < ss[j].rank
+	}
+	if len(ss[i].entry.phrase) != len(ss[j].entry.phrase) {
+		return len(ss[i].entry.phrase) < len(ss[j].entry.phrase)
+	}
+	return ss[i].entry.Label < ss[j].entry.Label
+}
@@ -1,11 +1,20 @@
 package config
 
-import "io/ioutil"
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+)
 
 func JsonFileName() string {
 	return "res/data/wwmu-gmzc.json";
 }
 
+// Snapshot of the movie info and coordinates caches that fresh databases are initialized with.
+func SeedFileName() string {
+	return "res/data/seed.json"
+}
+
 func ServiceUrl() string {
 	// Using 'http' instead of 'https' because App Engine will otherwise complain about the SSL certificate being invalid.
 	return "http://data.sfgov.org/resource/wwmu-gmzc.json";
@@ -23,10 +32,28 @@ func CloudDbSourceName() string {
 	return "root@cloudsql(uber-challenge-148819:europe-west1:movie-locations)/locations"
 }
 
+// Key of the Google Maps APIs or the empty string if the file `res/maps-api-key` doesn't exist.
 func MapsApiKey() string {
-	bytes, err := ioutil.ReadFile("res/maps-api-key")
+	return apiKey("res/maps-api-key")
+}
+
+// Key of the OMDB API or the empty string if the file `res/omdb-api-key` doesn't exist.
+func OmdbApiKey() string {
+	return apiKey("res/omdb-api-key")
+}
+
+// Key of the TMDB API or the empty string if the file `res/tmdb-api-key` doesn't exist.
+func TmdbApiKey() string {
+	return apiKey("res/tmdb-api-key")
+}
+
+func apiKey(fileName string) string {
+	bytes, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return ""
+	}
 	if err != nil {
 		panic(err)
 	}
-	return string(bytes)
+	return strings.TrimSpace(string(bytes))
 }
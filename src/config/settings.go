@@ -0,0 +1,294 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// Optional JSON object with settings that override the defaults given by the functions below.
+const settingsFileName = "res/settings.json"
+
+var settings = loadSettings(settingsFileName)
+
+func loadSettings(fileName string) map[string]json.RawMessage {
+	values := make(map[string]json.RawMessage)
+	
+	bytes, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return values
+	}
+	if err != nil {
+		panic(err)
+	}
+	if err := json.Unmarshal(bytes, &values); err != nil {
+		panic(err)
+	}
+	return values
+}
+
+// Decode setting into `value` (which holds the default) if it's present.
+func setting(name string, value interface{}) {
+	raw, exists := settings[name]
+	if !exists {
+		return
+	}
+	if err := json.Unmarshal(raw, value); err != nil {
+		panic(err)
+	}
+}
+
+func stringSetSetting(name string) map[string]bool {
+	var values []string
+	setting(name, &values)
+	
+	set := make(map[string]bool)
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// Names of ingestion pipeline stages ("normalize", "validate", "enrich") to skip. The stages "read" and "store" are
+// required and run anyway.
+func DisabledPipelineStages() map[string]bool {
+	return stringSetSetting("disabled_pipeline_stages")
+}
+
+// Names of normalization, validation, and enrichment rules (e.g. "clear-not-available") to skip.
+func DisabledPipelineRules() map[string]bool {
+	return stringSetSetting("disabled_pipeline_rules")
+}
+
+// Maximum number of attempts of an outbound HTTP request.
+func HttpMaxAttempts() int {
+	attempts := 4
+	setting("http_max_attempts", &attempts)
+	return attempts
+}
+
+// Maximum size (in bytes) of the body of a response to an outbound HTTP request.
+func HttpMaxResponseBytes() int64 {
+	maxBytes := int64(8 << 20)
+	setting("http_max_response_bytes", &maxBytes)
+	return maxBytes
+}
+
+// Initial and maximum delay before retrying a failed outbound HTTP request. The delay is doubled (and jittered) after
+// each attempt.
+func HttpBackoffMillis() (int, int) {
+	initial := 200
+	max := 5000
+	setting("http_initial_backoff_ms", &initial)
+	setting("http_max_backoff_ms", &max)
+	return initial, max
+}
+
+// Number of consecutive failed requests to a host after which its circuit breaker opens, and the number of seconds
+// until a trial request is let through again.
+func CircuitBreakerSettings() (int, int) {
+	threshold := 5
+	cooldown := 30
+	setting("circuit_breaker_threshold", &threshold)
+	setting("circuit_breaker_cooldown_s", &cooldown)
+	return threshold, cooldown
+}
+
+// Sustained rate (requests per second) and burst size of the requests to an external API.
+type RateLimit struct {
+	PerSecond float64 `json:"per_second"`
+	Burst     int     `json:"burst"`
+}
+
+var defaultRateLimits = map[string]RateLimit{
+	"geocoder": {PerSecond: 10, Burst: 10},
+	// The usage policy of the public Nominatim service allows at most one request per second.
+	"nominatim": {PerSecond: 1, Burst: 1},
+	"omdb":     {PerSecond: 5, Burst: 5},
+	"tmdb":     {PerSecond: 4, Burst: 4},
+	"socrata":  {PerSecond: 1, Burst: 2},
+	"poster":   {PerSecond: 5, Burst: 5},
+}
+
+// Rate limit of the API with the given name ("geocoder", "nominatim", "omdb", "tmdb", "socrata", or "poster").
+func ApiRateLimit(api string) RateLimit {
+	var limits map[string]RateLimit
+	setting("rate_limits", &limits)
+	
+	limit := defaultRateLimits[api]
+	if l, exists := limits[api]; exists {
+		if l.PerSecond > 0 {
+			limit.PerSecond = l.PerSecond
+		}
+		if l.Burst > 0 {
+			limit.Burst = l.Burst
+		}
+	}
+	return limit
+}
+
+// Maximum number of concurrent outbound requests made by a single fan-out (like geocoding the locations of a movie).
+func FetchConcurrency() int {
+	concurrency := 8
+	setting("fetch_concurrency", &concurrency)
+	return concurrency
+}
+
+// Hours until a location name that wasn't found by the geocoder is looked up again (doubled after each consecutive
+// failure up to the maximum), and minutes until a lookup that failed for other reasons (like an exceeded quota) is
+// retried.
+func NegativeCacheSettings() (int, int, int) {
+	notFoundHours := 24
+	maxNotFoundHours := 30 * 24
+	otherMinutes := 60
+	setting("negative_cache_hours", &notFoundHours)
+	setting("negative_cache_max_hours", &maxNotFoundHours)
+	setting("negative_cache_other_minutes", &otherMinutes)
+	return notFoundHours, maxNotFoundHours, otherMinutes
+}
+
+// Number of location names that the background geocoding job processes between checkpoints, and the number of seconds
+// that a single run of the job may take (cron requests time out after 10 minutes).
+func GeocodeJobSettings() (int, int) {
+	batchSize := 50
+	budgetSeconds := 300
+	setting("geocode_job_batch_size", &batchSize)
+	setting("geocode_job_budget_s", &budgetSeconds)
+	return batchSize, budgetSeconds
+}
+
+// Southern and northern latitude and western and eastern longitude of the area that geocodes must be within. The
+// default covers San Francisco including Treasure Island and Alcatraz.
+func SfBoundingBox() (float32, float32, float32, float32) {
+	var box struct {
+		South float32 `json:"south"`
+		West  float32 `json:"west"`
+		North float32 `json:"north"`
+		East  float32 `json:"east"`
+	}
+	box.South, box.West, box.North, box.East = 37.70, -122.52, 37.84, -122.35
+	setting("sf_bounding_box", &box)
+	return box.South, box.West, box.North, box.East
+}
+
+// File with the offline gazetteer of San Francisco or the empty string to not use it.
+func GazetteerFileName() string {
+	fileName := "res/data/sf-gazetteer.json"
+	setting("gazetteer_file", &fileName)
+	return fileName
+}
+
+// Name of the geocoder to use ("google", "nominatim", or "fixture"; empty to choose based on whether a Maps API key is
+// present), the base URL of the Nominatim service, and the file with the fixture of the fixture geocoder.
+func GeocoderSettings() (string, string, string) {
+	name := ""
+	nominatimBaseUrl := "https://nominatim.openstreetmap.org"
+	fixtureFileName := "res/data/geocode-fixture.json"
+	setting("geocoder", &name)
+	setting("nominatim_base_url", &nominatimBaseUrl)
+	setting("geocoder_fixture_file", &fixtureFileName)
+	return name, nominatimBaseUrl, fixtureFileName
+}
+
+// User agent and email address identifying the app to Nominatim (as required by its usage policy), and the maximum
+// number of concurrent requests to it.
+func NominatimSettings() (string, string, int) {
+	userAgent := "sf-movie-locations (https://uber-challenge-148819.appspot.com)"
+	email := ""
+	concurrency := 1
+	setting("nominatim_user_agent", &userAgent)
+	setting("nominatim_email", &email)
+	setting("nominatim_concurrency", &concurrency)
+	return userAgent, email, concurrency
+}
+
+// Name of the movie metadata provider to use ("omdb", "tmdb", or "fixture"), the base URLs of the OMDB and TMDB APIs, and
+// the file with the fixture of the fixture provider.
+func MetadataSettings() (string, string, string, string) {
+	name := "omdb"
+	omdbBaseUrl := "http://www.omdbapi.com"
+	tmdbBaseUrl := "https://api.themoviedb.org/3"
+	fixtureFileName := "res/data/metadata-fixture.json"
+	setting("metadata_provider", &name)
+	setting("omdb_base_url", &omdbBaseUrl)
+	setting("tmdb_base_url", &tmdbBaseUrl)
+	setting("metadata_fixture_file", &fixtureFileName)
+	return name, omdbBaseUrl, tmdbBaseUrl, fixtureFileName
+}
+
+// Hours until cached movie info (of movies that were found and of ones that weren't, respectively) expires, and minutes
+// until a failed refresh of expired info is attempted again.
+func MovieInfoTtlSettings() (int, int, int) {
+	ttlHours := 7 * 24
+	notFoundTtlHours := 30 * 24
+	retryMinutes := 60
+	setting("movie_info_ttl_hours", &ttlHours)
+	setting("movie_info_not_found_ttl_hours", &notFoundTtlHours)
+	setting("movie_info_retry_minutes", &retryMinutes)
+	return ttlHours, notFoundTtlHours, retryMinutes
+}
+
+// Number of expired movie infos that the background refresher fetches between storing them, and the number of seconds
+// that a single run of the refresher may take.
+func MovieInfoRefreshSettings() (int, int) {
+	batchSize := 20
+	budgetSeconds := 300
+	setting("movie_info_refresh_batch_size", &batchSize)
+	setting("movie_info_refresh_budget_s", &budgetSeconds)
+	return batchSize, budgetSeconds
+}
+
+// Minimum score (in [0, 1]) of a candidate found by searching the metadata provider to be accepted as the match of a
+// movie, and the number of the best candidates by title and year whose details are fetched to be scored by director.
+func MovieMatchSettings() (float64, int) {
+	minScore := 0.5
+	maxCandidates := 3
+	setting("movie_match_min_score", &minScore)
+	setting("movie_match_max_candidates", &maxCandidates)
+	return minScore, maxCandidates
+}
+
+// Widths (in pixels) of the thumbnails of cached posters by size name, the number of days that browsers may cache
+// served posters, and the maximum number of pixels of a downloaded poster (larger ones are refused rather than decoded).
+func PosterSettings() (map[string]int, int, int) {
+	widths := map[string]int{"small": 92, "medium": 185, "large": 342}
+	maxAgeDays := 30
+	maxPixels := 12000000
+	setting("poster_widths", &widths)
+	setting("poster_max_age_days", &maxAgeDays)
+	setting("poster_max_pixels", &maxPixels)
+	return widths, maxAgeDays, maxPixels
+}
+
+// Sources ("datasf" or "metadata") of the fields "year", "director", "writers", and "actors" of movies in order of
+// priority. The metadata is preferred for credits as it's more complete, while the data set is preferred for the
+// release year as it's what the locations were recorded for. Unknown fields and sources are refused.
+func CreditSourcePriority() map[string][]string {
+	priority := map[string][]string{
+		"year":     {"datasf", "metadata"},
+		"director": {"metadata", "datasf"},
+		"writers":  {"metadata", "datasf"},
+		"actors":   {"metadata", "datasf"},
+	}
+	var configured map[string][]string
+	setting("credit_source_priority", &configured)
+	for field, sources := range configured {
+		if _, exists := priority[field]; !exists {
+			panic(fmt.Sprintf("Unknown field '%s' in setting 'credit_source_priority'", field))
+		}
+		for _, source := range sources {
+			if source != "datasf" && source != "metadata" {
+				panic(fmt.Sprintf("Unknown source '%s' of field '%s' in setting 'credit_source_priority'", source, field))
+			}
+		}
+		priority[field] = sources
+	}
+	return priority
+}
+
+// Settings that are only used occasionally are checked when the settings are loaded such that mistakes don't go
+// unnoticed until then.
+func init() {
+	CreditSourcePriority()
+}
@@ -0,0 +1,300 @@
+package fetch
+
+import (
+	"src/config"
+	"src/logging"
+	"src/watch"
+	"appengine"
+	"appengine/urlfetch"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// All outbound HTTP requests go through `Get`, which retries transient failures (network errors and 429/5xx
+// responses) with jittered exponential backoff. Consecutive failures against a host open a circuit breaker for that
+// host such that requests fail fast until a trial request is let through after a cooldown period.
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// Status of the requests made to a host by this instance.
+type HostStatus struct {
+	Host                string
+	State               BreakerState
+	ConsecutiveFailures int
+	OpenedAt            time.Time
+	ProbeStartedAt      time.Time
+	Requests            int
+	Retries             int
+	Failures            int
+	Rejected            int
+}
+
+// Error returned for a response with a non-2xx status code. The URL is stored without its query as that may contain
+// API keys (which would otherwise end up in logs and failure reasons).
+type StatusError struct {
+	Url        string
+	StatusCode int
+}
+
+func newStatusError(uri string, statusCode int) *StatusError {
+	return &StatusError{Url: urlWithoutQuery(uri), StatusCode: statusCode}
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Request to '%s' failed with status %d", e.Url, e.StatusCode)
+}
+
+// Error of a response whose body exceeds the configured maximum size. The URL is stored without its query (see
+// `StatusError`).
+type ResponseTooLargeError struct {
+	Url      string
+	MaxBytes int64
+}
+
+func newResponseTooLargeError(uri string, maxBytes int64) *ResponseTooLargeError {
+	return &ResponseTooLargeError{Url: urlWithoutQuery(uri), MaxBytes: maxBytes}
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("Response of '%s' exceeds %d bytes", e.Url, e.MaxBytes)
+}
+
+// The given URL without its query and fragment.
+func urlWithoutQuery(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		// Don't risk revealing the query of a URL that can't be parsed.
+		if idx := strings.IndexAny(uri, "?#"); idx >= 0 {
+			return uri[:idx]
+		}
+		return uri
+	}
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	return parsed.String()
+}
+
+var hostStatusesMutex = &sync.Mutex{}
+var hostStatuses = make(map[string]*HostStatus)
+
+// Copies of the statuses of all hosts that have been requested, sorted by host.
+func HostStatuses() []HostStatus {
+	hostStatusesMutex.Lock()
+	defer hostStatusesMutex.Unlock()
+	
+	statuses := make([]HostStatus, 0, len(hostStatuses))
+	for _, s := range hostStatuses {
+		statuses = append(statuses, *s)
+	}
+	sort.Sort(byHost(statuses))
+	return statuses
+}
+
+type byHost []HostStatus
+
+func (ss byHost) Len() int {
+	return len(ss)
+}
+func (ss byHost) Swap(i, j int) {
+	ss[i], ss[j] = ss[j], ss[i]
+}
+func (ss byHost) Less(i, j int) bool {
+	return ss[i].Host < ss[j].Host
+}
+
+// Fetch the body of the given URL. Every attempt waits for the rate limiter of the API.
+func Get(uri string, limiter *RateLimiter, ctx appengine.Context, log logging.Logger) ([]byte, error) {
+	return GetWithHeader(uri, nil, limiter, ctx, log)
+}
+
+// Fetch the body of the given URL, sending the given header fields (like "User-Agent") with the request.
+func GetWithHeader(uri string, header http.Header, limiter *RateLimiter, ctx appengine.Context, log logging.Logger) ([]byte, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	host := parsed.Host
+	
+	maxAttempts := config.HttpMaxAttempts()
+	initialBackoff, maxBackoff := config.HttpBackoffMillis()
+	
+	sw := watch.NewStopWatch()
+	for attempt := 1; ; attempt++ {
+		// Wait before asking the circuit breaker such that a trial request isn't held up by the rate limiter.
+		limiter.Wait()
+		if err := allowRequest(host); err != nil {
+			return nil, err
+		}
+		
+		bytes, retryAfter, err := get(uri, header, ctx)
+		recordResult(host, attempt > 1, err)
+		if err == nil {
+			log.Infof("Fetched %d bytes from '%s' in %d ms", len(bytes), host, sw.TotalElapsedTimeMillis())
+			return bytes, nil
+		}
+		if !isTransient(err) || attempt >= maxAttempts {
+			return nil, err
+		}
+		
+		// Servers may ask for long delays (like "Retry-After: 86400"), which would stall the request.
+		if maxDelay := time.Duration(maxBackoff) * time.Millisecond; retryAfter > maxDelay {
+			retryAfter = maxDelay
+		}
+		delay := backoff(attempt, initialBackoff, maxBackoff)
+		if retryAfter > 0 {
+			// Make all requests to the API wait as requested by the provider.
+			limiter.Pause(retryAfter)
+		}
+		log.Warningf("Attempt %d of request to '%s' failed (retrying in %d ms): %s", attempt, host, delay / time.Millisecond, err.Error())
+		time.Sleep(delay)
+	}
+}
+
+// Perform a single request. Returns the delay requested by a "Retry-After" header (in seconds) if any.
+func get(uri string, header http.Header, ctx appengine.Context) ([]byte, time.Duration, error) {
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	
+	client := urlfetch.Client(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		// Network errors include the URL as well.
+		if urlErr, ok := err.(*url.Error); ok {
+			urlErr.URL = urlWithoutQuery(urlErr.URL)
+		}
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var retryAfter time.Duration
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(s) * time.Second
+		}
+		return nil, retryAfter, newStatusError(uri, resp.StatusCode)
+	}
+	
+	// Read one byte more than allowed to tell whether the body exceeds the limit.
+	maxBytes := config.HttpMaxResponseBytes()
+	bytes, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBytes + 1))
+	if err == nil && int64(len(bytes)) > maxBytes {
+		return nil, 0, newResponseTooLargeError(uri, maxBytes)
+	}
+	return bytes, 0, err
+}
+
+// Network errors (except for too large responses) and responses with status 429 (too many requests) or 5xx are
+// considered transient.
+func isTransient(err error) bool {
+	if statusErr, ok := err.(*StatusError); ok {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	if _, tooLarge := err.(*ResponseTooLargeError); tooLarge {
+		return false
+	}
+	_, breakerErr := err.(*BreakerOpenError)
+	return !breakerErr
+}
+
+// Random delay between zero and the exponentially growing cap ("full jitter").
+func backoff(attempt int, initialMillis int, maxMillis int) time.Duration {
+	capMillis := initialMillis << uint(attempt - 1)
+	if capMillis > maxMillis || capMillis <= 0 {
+		capMillis = maxMillis
+	}
+	return time.Duration(rand.Int63n(int64(capMillis) + 1)) * time.Millisecond
+}
+
+// Error returned without making a request when the circuit breaker of the host is open.
+type BreakerOpenError struct {
+	Host string
+}
+
+func (e *BreakerOpenError) Error() string {
+	return fmt.Sprintf("Circuit breaker for host '%s' is open", e.Host)
+}
+
+func hostStatus(host string) *HostStatus {
+	s, exists := hostStatuses[host]
+	if !exists {
+		s = &HostStatus{Host: host, State: BreakerClosed}
+		hostStatuses[host] = s
+	}
+	return s
+}
+
+// Check whether a request to the host is allowed by its circuit breaker. An open breaker lets a single trial request
+// through (and becomes half-open) once the cooldown period has passed. Concurrent requests are rejected while the trial
+// request is in flight, but if it hasn't reported back within the cooldown period (like when it was abandoned), the next
+// request becomes the trial request such that the breaker can't get stuck half-open.
+func allowRequest(host string) error {
+	hostStatusesMutex.Lock()
+	defer hostStatusesMutex.Unlock()
+	
+	_, cooldownSeconds := config.CircuitBreakerSettings()
+	cooldown := time.Duration(cooldownSeconds) * time.Second
+	
+	s := hostStatus(host)
+	switch s.State {
+	case BreakerOpen:
+		if time.Since(s.OpenedAt) < cooldown {
+			s.Rejected++
+			return &BreakerOpenError{Host: host}
+		}
+		s.State = BreakerHalfOpen
+		s.ProbeStartedAt = time.Now()
+	case BreakerHalfOpen:
+		if time.Since(s.ProbeStartedAt) < cooldown {
+			s.Rejected++
+			return &BreakerOpenError{Host: host}
+		}
+		s.ProbeStartedAt = time.Now()
+	}
+	s.Requests++
+	return nil
+}
+
+// Update the circuit breaker of the host with the result of a request. Only transient errors count as failures as
+// e.g. a 404 response shows that the host is up.
+func recordResult(host string, retry bool, err error) {
+	hostStatusesMutex.Lock()
+	defer hostStatusesMutex.Unlock()
+	
+	s := hostStatus(host)
+	if retry {
+		s.Retries++
+	}
+	if err == nil || !isTransient(err) {
+		s.State = BreakerClosed
+		s.ConsecutiveFailures = 0
+		return
+	}
+	
+	s.Failures++
+	s.ConsecutiveFailures++
+	threshold, _ := config.CircuitBreakerSettings()
+	if s.State == BreakerHalfOpen || s.ConsecutiveFailures >= threshold {
+		s.State = BreakerOpen
+		s.OpenedAt = time.Now()
+	}
+}
@@ -0,0 +1,43 @@
+package fetch
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUrlWithoutQuery(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"https://api.themoviedb.org/3/search/movie?query=Vertigo&api_key=secret", "https://api.themoviedb.org/3/search/movie"},
+		{"https://www.omdbapi.com/?s=Vertigo&r=json&apikey=secret", "https://www.omdbapi.com/"},
+		{"https://example.com/poster.jpg", "https://example.com/poster.jpg"},
+		{"https://example.com/page#key=secret", "https://example.com/page"},
+	}
+	for _, test := range tests {
+		if got := urlWithoutQuery(test.uri); got != test.want {
+			t.Errorf("urlWithoutQuery(%q) = %q, want %q", test.uri, got, test.want)
+		}
+	}
+}
+
+func TestErrorsDontRevealKeys(t *testing.T) {
+	uris := []string{
+		"https://maps.googleapis.com/maps/api/geocode/json?address=Union+Square,San+Francisco,+CA&key=secret",
+		"https://www.omdbapi.com/?s=Vertigo&r=json&apikey=secret",
+		"https://api.themoviedb.org/3/search/movie?query=Vertigo&api_key=secret",
+		"%zz?key=secret",
+	}
+	for _, uri := range uris {
+		errs := []error{
+			newStatusError(uri, 403),
+			newResponseTooLargeError(uri, 1024),
+		}
+		for _, err := range errs {
+			if msg := err.Error(); strings.Contains(msg, "secret") {
+				t.Errorf("Error of request to %q reveals the key: %s", uri, msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package fetch
+
+import (
+	"src/data/types"
+	"src/logging"
+	"appengine"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// Offline index of well-known places in San Francisco: landmarks (with aliases), street intersections, and
+// neighborhood centroids. Names are indexed in their normalized form (see `NormalizeLocationName`).
+type Gazetteer struct {
+	landmarks     map[string]types.Geocode
+	intersections map[string]types.Geocode
+	neighborhoods map[string]types.Geocode
+}
+
+type gazetteerPlace struct {
+	Name    string
+	Aliases []string
+	Lat     float32
+	Lng     float32
+}
+
+type gazetteerIntersection struct {
+	Streets [2]string
+	Lat     float32
+	Lng     float32
+}
+
+func LoadGazetteer(fileName string) (*Gazetteer, error) {
+	bytes, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	
+	var file struct {
+		Landmarks     []gazetteerPlace
+		Intersections []gazetteerIntersection
+		Neighborhoods []gazetteerPlace
+	}
+	if err := json.Unmarshal(bytes, &file); err != nil {
+		return nil, err
+	}
+	
+	g := &Gazetteer{
+		landmarks:     indexPlaces(file.Landmarks, "landmark", types.LocationGeometricCenter),
+		intersections: make(map[string]types.Geocode),
+		neighborhoods: indexPlaces(file.Neighborhoods, "neighborhood", types.LocationApproximate),
+	}
+	for _, i := range file.Intersections {
+		g.intersections[intersectionKey(i.Streets)] = types.Geocode{
+			Coordinates:      types.Coordinates{Lat: i.Lat, Lng: i.Lng},
+			FormattedAddress: i.Streets[0] + " & " + i.Streets[1],
+			LocationType:     types.LocationGeometricCenter,
+			PlaceTypes:       []string{"intersection"},
+		}
+	}
+	return g, nil
+}
+
+func indexPlaces(places []gazetteerPlace, placeType string, locationType string) map[string]types.Geocode {
+	index := make(map[string]types.Geocode)
+	for _, p := range places {
+		g := types.Geocode{
+			Coordinates:      types.Coordinates{Lat: p.Lat, Lng: p.Lng},
+			FormattedAddress: p.Name,
+			LocationType:     locationType,
+			PlaceTypes:       []string{placeType},
+		}
+		index[NormalizeLocationName(p.Name)] = g
+		for _, alias := range p.Aliases {
+			index[NormalizeLocationName(alias)] = g
+		}
+	}
+	return index
+}
+
+// Street suffixes that are left out when matching intersections (such that "Mason & California Streets" matches
+// "California St & Mason").
+var streetSuffixWords = map[string]bool{
+	"street": true, "avenue": true, "boulevard": true, "drive": true, "place": true, "road": true, "lane": true,
+	"way": true, "terrace": true, "court": true,
+}
+
+// Key of an intersection that is independent of the order and suffixes of the streets.
+func intersectionKey(streets [2]string) string {
+	names := make([]string, 2)
+	for i, s := range streets {
+		words := strings.Fields(NormalizeLocationName(s))
+		if len(words) > 1 && streetSuffixWords[words[len(words) - 1]] {
+			words = words[:len(words) - 1]
+		}
+		names[i] = strings.Join(words, " ")
+	}
+	sort.Strings(names)
+	return names[0] + "|" + names[1]
+}
+
+// Geocoder resolving queries against the landmarks and intersections of a gazetteer or, if `Neighborhoods` is set,
+// against its neighborhood centroids (which are too coarse to be tried before more precise geocoders).
+type GazetteerGeocoder struct {
+	Gazetteer     *Gazetteer
+	Neighborhoods bool
+}
+
+func (g *GazetteerGeocoder) Name() string {
+	if g.Neighborhoods {
+		return "gazetteer-neighborhood"
+	}
+	return "gazetteer"
+}
+
+func (g *GazetteerGeocoder) Geocode(query string, ctx appengine.Context, log logging.Logger) (types.Geocode, error) {
+	if g.Neighborhoods {
+		if geocode, exists := g.Gazetteer.neighborhoods[NormalizeLocationName(trimNeighborhood(query))]; exists {
+			return geocode, nil
+		}
+		return types.Geocode{}, ErrAddressNotFound
+	}
+	
+	if geocode, exists := g.Gazetteer.landmarks[NormalizeLocationName(query)]; exists {
+		return geocode, nil
+	}
+	if parts := ParseLocationName(query); parts.Intersection[0] != "" {
+		if geocode, exists := g.Gazetteer.intersections[intersectionKey(parts.Intersection)]; exists {
+			return geocode, nil
+		}
+	}
+	return types.Geocode{}, ErrAddressNotFound
+}
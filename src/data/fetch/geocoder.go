@@ -0,0 +1,328 @@
+package fetch
+
+import (
+	"src/config"
+	"src/data/types"
+	"src/logging"
+	"appengine"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrAddressNotFound = errors.New("Address not found")
+var ErrOverQueryLimit = errors.New("Query limit exceeded")
+
+// Service resolving a single query (like an address or the name of a landmark) in San Francisco into coordinates.
+// Returns `ErrAddressNotFound` if the query has no match. The source of the result is set by the caller.
+type Geocoder interface {
+	Name() string
+	Geocode(query string, ctx appengine.Context, log logging.Logger) (types.Geocode, error)
+}
+
+// Geocoder whose service limits the number of concurrent requests.
+type concurrencyLimited interface {
+	MaxConcurrency() int
+}
+
+// Number of locations to geocode concurrently: the configured fetch concurrency, lowered to the limit of any of the
+// geocoders.
+func geocodingConcurrency(geocoders []Geocoder) int {
+	concurrency := config.FetchConcurrency()
+	for _, g := range geocoders {
+		if l, ok := g.(concurrencyLimited); ok && l.MaxConcurrency() > 0 && l.MaxConcurrency() < concurrency {
+			concurrency = l.MaxConcurrency()
+		}
+	}
+	return concurrency
+}
+
+// Geocoders to try in order: The offline gazetteer (if present) first resolves landmarks and intersections, then the
+// network geocoder selected by configuration is tried, and finally the neighborhood centroids of the gazetteer.
+func NewGeocoders() []Geocoder {
+	geocoder := newNetworkGeocoder()
+	
+	fileName := config.GazetteerFileName()
+	if fileName == "" {
+		return []Geocoder{geocoder}
+	}
+	gazetteer, err := LoadGazetteer(fileName)
+	if err != nil {
+		panic(err)
+	}
+	return []Geocoder{
+		&GazetteerGeocoder{Gazetteer: gazetteer},
+		geocoder,
+		&GazetteerGeocoder{Gazetteer: gazetteer, Neighborhoods: true},
+	}
+}
+
+// Geocoder selected by configuration. Unless configured otherwise, the Google geocoder is used if a Maps API key is
+// present and Nominatim otherwise.
+func newNetworkGeocoder() Geocoder {
+	name, nominatimBaseUrl, fixtureFileName := config.GeocoderSettings()
+	apiKey := config.MapsApiKey()
+	if name == "" {
+		name = "nominatim"
+		if apiKey != "" {
+			name = "google"
+		}
+	}
+	
+	switch name {
+	case "google":
+		return &GoogleGeocoder{ApiKey: apiKey}
+	case "nominatim":
+		userAgent, email, concurrency := config.NominatimSettings()
+		return &NominatimGeocoder{BaseUrl: nominatimBaseUrl, UserAgent: userAgent, Email: email, Concurrency: concurrency}
+	case "fixture":
+		return &FixtureGeocoder{FileName: fixtureFileName}
+	}
+	panic(fmt.Sprintf("Unknown geocoder '%s'", name))
+}
+
+// Geocoder using the Google Geocoding API.
+type GoogleGeocoder struct {
+	ApiKey string
+}
+
+// How long to pause all geocoding requests when the quota of the Geocoding API is exceeded.
+const overQueryLimitPause = 2 * time.Second
+
+type googleGeocodeResponse struct {
+	Results []struct {
+		Formatted_Address string
+		Types             []string
+		Geometry          struct {
+			Location      types.Coordinates
+			Location_Type string
+			Viewport      struct { Northeast, Southwest types.Coordinates }
+		}
+	}
+	Status  string
+}
+
+func (g *GoogleGeocoder) Name() string {
+	return "google"
+}
+
+func (g *GoogleGeocoder) Geocode(query string, ctx appengine.Context, log logging.Logger) (types.Geocode, error) {
+	uri := fmt.Sprintf(
+		"https://maps.googleapis.com/maps/api/geocode/json?address=%s,San+Francisco,+CA",
+		url.QueryEscape(query),
+	)
+	
+	var res googleGeocodeResponse
+	for attempt := 1; ; attempt++ {
+		log.Infof("Fetching coordinates of '%s' from URL '%s'", query, uri)
+		bytes, err := Get(uri + "&key=" + url.QueryEscape(g.ApiKey), GeocoderLimiter, ctx, log)
+		if err != nil {
+			return types.Geocode{}, err
+		}
+		if err := json.Unmarshal(bytes, &res); err != nil {
+			return types.Geocode{}, err
+		}
+		if res.Status != "OVER_QUERY_LIMIT" {
+			break
+		}
+		
+		// The quota is reported in the body of an otherwise successful response.
+		GeocoderLimiter.Pause(overQueryLimitPause)
+		if attempt >= config.HttpMaxAttempts() {
+			return types.Geocode{}, ErrOverQueryLimit
+		}
+		log.Warningf("Query limit exceeded while fetching coordinates of '%s' (attempt %d)", query, attempt)
+	}
+	
+	if res.Status != "OK" || len(res.Results) == 0 {
+		return types.Geocode{}, ErrAddressNotFound
+	}
+	
+	var geocodes []types.Geocode
+	for _, result := range res.Results {
+		if result.Formatted_Address == "California, USA" {
+			// Generic response; consider this a non-match.
+			continue
+		}
+		
+		viewport := result.Geometry.Viewport
+		geocodes = append(geocodes, types.Geocode{
+			Coordinates:      result.Geometry.Location,
+			FormattedAddress: result.Formatted_Address,
+			LocationType:     result.Geometry.Location_Type,
+			PlaceTypes:       result.Types,
+			Viewport:         types.Bounds{
+				South: viewport.Southwest.Lat,
+				West:  viewport.Southwest.Lng,
+				North: viewport.Northeast.Lat,
+				East:  viewport.Northeast.Lng,
+			},
+		})
+	}
+	return firstWithAlternatives(geocodes)
+}
+
+// The first of the results of a query with the remaining ones as its alternatives.
+func firstWithAlternatives(geocodes []types.Geocode) (types.Geocode, error) {
+	if len(geocodes) == 0 {
+		return types.Geocode{}, ErrAddressNotFound
+	}
+	geocode := geocodes[0]
+	geocode.Alternatives = geocodes[1:]
+	return geocode, nil
+}
+
+// Maximum number of results requested from Nominatim. Results beyond the first are candidates for review.
+const nominatimLimit = 5
+
+// Geocoder using the search API of Nominatim (OpenStreetMap) or a compatible service at the given base URL. Requests
+// identify the app by its user agent and email address (if any) and are limited by their own rate limiter and
+// concurrency, as the public service allows only one request at a time and per second.
+type NominatimGeocoder struct {
+	BaseUrl     string
+	UserAgent   string
+	Email       string
+	Concurrency int
+}
+
+func (g *NominatimGeocoder) Name() string {
+	return "nominatim"
+}
+
+func (g *NominatimGeocoder) MaxConcurrency() int {
+	return g.Concurrency
+}
+
+func (g *NominatimGeocoder) Geocode(query string, ctx appengine.Context, log logging.Logger) (types.Geocode, error) {
+	uri := fmt.Sprintf(
+		"%s/search?format=json&limit=%d&countrycodes=us&q=%s",
+		strings.TrimRight(g.BaseUrl, "/"),
+		nominatimLimit,
+		url.QueryEscape(query + ", San Francisco, CA"),
+	)
+	if g.Email != "" {
+		uri += "&email=" + url.QueryEscape(g.Email)
+	}
+	header := http.Header{}
+	if g.UserAgent != "" {
+		header.Set("User-Agent", g.UserAgent)
+	}
+	
+	log.Infof("Fetching coordinates of '%s' from URL '%s'", query, uri)
+	bytes, err := GetWithHeader(uri, header, NominatimLimiter, ctx, log)
+	if err != nil {
+		return types.Geocode{}, err
+	}
+	
+	// Coordinates are given as strings and the bounding box as [south, north, west, east].
+	var res []struct {
+		Lat          string
+		Lon          string
+		Display_Name string
+		Class        string
+		Type         string
+		Boundingbox  []string
+	}
+	if err := json.Unmarshal(bytes, &res); err != nil {
+		return types.Geocode{}, err
+	}
+	
+	geocodes := make([]types.Geocode, len(res))
+	for i, r := range res {
+		lat, err := strconv.ParseFloat(r.Lat, 32)
+		if err != nil {
+			return types.Geocode{}, err
+		}
+		lng, err := strconv.ParseFloat(r.Lon, 32)
+		if err != nil {
+			return types.Geocode{}, err
+		}
+		
+		geocodes[i] = types.Geocode{
+			Coordinates:      types.Coordinates{Lat: float32(lat), Lng: float32(lng)},
+			FormattedAddress: r.Display_Name,
+			LocationType:     nominatimLocationType(r.Class),
+			PlaceTypes:       []string{r.Class, r.Type},
+		}
+		if bb := r.Boundingbox; len(bb) == 4 {
+			var bounds [4]float64
+			for j, str := range bb {
+				if bounds[j], err = strconv.ParseFloat(str, 32); err != nil {
+					return types.Geocode{}, err
+				}
+			}
+			geocodes[i].Viewport = types.Bounds{
+				South: float32(bounds[0]),
+				North: float32(bounds[1]),
+				West:  float32(bounds[2]),
+				East:  float32(bounds[3]),
+			}
+		}
+	}
+	return firstWithAlternatives(geocodes)
+}
+
+// Areas (like neighborhoods and cities) are only approximate locations and streets are represented by their centers.
+func nominatimLocationType(class string) string {
+	switch class {
+	case "place", "boundary":
+		return types.LocationApproximate
+	case "highway":
+		return types.LocationGeometricCenter
+	}
+	return types.LocationRooftop
+}
+
+// Geocoder looking up queries (case-insensitively) in a JSON file mapping queries to coordinates like
+// `{"Coit Tower": {"lat": 37.8024, "lng": -122.4058}}`. Intended for development and tests without an API key or
+// network access.
+type FixtureGeocoder struct {
+	FileName string
+	once     sync.Once
+	coords   map[string]types.Coordinates
+	err      error
+}
+
+func (g *FixtureGeocoder) Name() string {
+	return "fixture"
+}
+
+func (g *FixtureGeocoder) load() {
+	bytes, err := ioutil.ReadFile(g.FileName)
+	if err != nil {
+		g.err = err
+		return
+	}
+	
+	var fixture map[string]types.Coordinates
+	if err := json.Unmarshal(bytes, &fixture); err != nil {
+		g.err = err
+		return
+	}
+	
+	g.coords = make(map[string]types.Coordinates)
+	for query, c := range fixture {
+		g.coords[strings.ToLower(query)] = c
+	}
+}
+
+func (g *FixtureGeocoder) Geocode(query string, ctx appengine.Context, log logging.Logger) (types.Geocode, error) {
+	g.once.Do(g.load)
+	if g.err != nil {
+		return types.Geocode{}, g.err
+	}
+	
+	c, exists := g.coords[strings.ToLower(strings.TrimSpace(query))]
+	if !exists {
+		return types.Geocode{}, ErrAddressNotFound
+	}
+	log.Infof("Found coordinates of '%s' in fixture '%s'", query, g.FileName)
+	return types.Geocode{Coordinates: c, FormattedAddress: query}, nil
+}
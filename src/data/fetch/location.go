@@ -1,114 +1,152 @@
 package fetch
 
 import (
+	"src/config"
+	"errors"
 	"src/logging"
+	"strings"
 	"sync"
 	"time"
-	"fmt"
-	"net/url"
-	"src/watch"
-	"appengine/urlfetch"
-	"io/ioutil"
-	"encoding/json"
-	"errors"
-	"strings"
 	"appengine"
 	"src/data/types"
 )
 
-func FetchLocationCoordinates(mapsApiKey string, locName string, ctx appengine.Context, logger logging.Logger) (types.Coordinates, error) {
-	uri := fmt.Sprintf(
-		"https://maps.googleapis.com/maps/api/geocode/json?address=%s,San+Fransisco,+CA&key=%s",
-		url.QueryEscape(locName),
-		mapsApiKey,
-	)
-	
-	sw := watch.NewStopWatch()
-	
-	logger.Infof("Fetching coordinates of location '%s' from URL '%s'", locName, uri)
-	client := urlfetch.Client(ctx)
-	resp, err := client.Get(uri)
-	if err != nil {
-		return types.Coordinates{}, err
-	}
-	defer resp.Body.Close()
-	
-	bytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return types.Coordinates{}, err
-	}
-	
-	var res struct {
-		Results []struct {
-			Formatted_Address string
-			Geometry          struct { Location types.Coordinates }
+// Fetch the coordinates of a location by trying the queries derived from the parts of its name in order with each of
+// the geocoders in turn. Results outside of the configured bounding box of San Francisco are rejected. Only queries that
+// were answered without an acceptable match fall through to the next one; any other error is returned right away. The
+// error is `ErrAddressNotFound` if none of the queries matched.
+func FetchLocationCoordinates(geocoders []Geocoder, locName string, parts types.LocationParts, ctx appengine.Context, logger logging.Logger) (types.Geocode, error) {
+	bounds := SfBounds()
+	queries := geocodingQueries(locName, parts)
+	for _, geocoder := range geocoders {
+		for _, query := range queries {
+			geocode, err := geocoder.Geocode(query, ctx, logger)
+			if err == nil && !bounds.Contains(geocode.Coordinates) {
+				logger.Warningf(
+					"Rejecting coordinates (%f, %f) of '%s' for query '%s' as they're outside of San Francisco",
+					geocode.Coordinates.Lat,
+					geocode.Coordinates.Lng,
+					geocode.FormattedAddress,
+					query,
+				)
+				err = ErrAddressNotFound
+			}
+			if err == nil {
+				var alternatives []types.Geocode
+				for _, a := range geocode.Alternatives {
+					if bounds.Contains(a.Coordinates) {
+						a.Source = geocoder.Name()
+						a.Query = query
+						alternatives = append(alternatives, a)
+					}
+				}
+				geocode.Alternatives = alternatives
+				geocode.Source = geocoder.Name()
+				geocode.Query = query
+				return geocode, nil
+			}
+			if err != ErrAddressNotFound {
+				// Transient errors (like an exceeded quota or an open circuit breaker) must not let a less precise
+				// geocoder (like the neighborhood centroids) answer, as its result would be cached. The lookup is
+				// retried later instead.
+				return types.Geocode{}, err
+			}
+			logger.Debugf("Query '%s' for location '%s' failed with geocoder '%s': %s", query, locName, geocoder.Name(), err.Error())
 		}
-		Status  string
-	}
-	if err := json.Unmarshal(bytes, &res); err != nil {
-		return types.Coordinates{}, err
 	}
-	
-	logger.Infof("Fetched %d bytes in %d ms", len(bytes), sw.TotalElapsedTimeMillis())
-	
-	if (len(res.Results) == 0) {
-		return types.Coordinates{}, errors.New("Address not found")
+	return types.Geocode{}, ErrAddressNotFound
+}
+
+const (
+	ReviewMultipleResults = "multiple results"
+	ReviewFallbackQuery   = "matched sub-location"
+	ReviewAfterRejection  = "candidates were rejected"
+)
+
+// Reason for an admin to review the geocode of a location name or the empty string if it's unambiguous: The query
+// that matched had several results or was derived from only part of the name (like a parenthesized landmark or a
+// neighborhood) because the more specific queries had no match.
+func ReviewReason(locName string, parts types.LocationParts, geocode types.Geocode) string {
+	if len(geocode.Alternatives) > 0 {
+		return ReviewMultipleResults
 	}
-	
-	result := res.Results[0]
-	if res.Status != "OK" || result.Formatted_Address == "California, USA" {
-		// Error or generic response. Look for nested address.
-		leftParIdx := strings.Index(locName, "(")
-		rightParIdx := strings.Index(locName, ")")
-		
-		if 0 <= leftParIdx && leftParIdx < rightParIdx {
-			subLocation := strings.TrimSpace(locName[leftParIdx + 1 : rightParIdx])
-			return FetchLocationCoordinates(mapsApiKey, subLocation, ctx, logger)
+	for _, query := range geocodingQueries(locName, parts) {
+		if query == geocode.Query {
+			return ""
 		}
-		
-		commaIdx := strings.Index(locName, ",")
-		if 0 <= commaIdx && commaIdx < len(locName) {
-			subLocation := strings.TrimSpace(locName[commaIdx + 1 : ])
-			return FetchLocationCoordinates(mapsApiKey, subLocation, ctx, logger)
+		if strings.EqualFold(query, locName) {
+			// Queries after the full name are fallbacks.
+			return ReviewFallbackQuery
 		}
-		
-		// Consider this a non-match.
-		return types.Coordinates{}, errors.New("Address not found")
 	}
-	return result.Geometry.Location, nil
+	return ""
+}
+
+// Configured bounding box of San Francisco that coordinates must be within.
+func SfBounds() types.Bounds {
+	south, west, north, east := config.SfBoundingBox()
+	return types.Bounds{South: south, West: west, North: north, East: east}
 }
 
-func FetchMissingLocationNames(coords map[string]*types.Coordinates, delay func (int) int, mapsApiKey string, ctx appengine.Context, logger logging.Logger) {
-	// Fetch geo locations in parallel.
+// Fetch the coordinates of the locations in `geocodes` concurrently (as far as the geocoders allow) and set the ones that
+// are found.
+func FetchMissingLocationNames(geocodes map[string]*types.Geocode, parts map[string]types.LocationParts, geocoders []Geocoder, ctx appengine.Context, logger logging.Logger) TaskResults {
 	mutex := &sync.Mutex{}
-	ch := make(chan bool)
 	
-	count := 0
-	for n := range coords {
-		d := delay(count)
-		
-		go func (name string) {
-			// Allow caller to block on this routine.
-			defer func() { ch <- true }()
-			
-			time.Sleep(time.Duration(d) * time.Millisecond)
-			
-			cs, err := FetchLocationCoordinates(mapsApiKey, name, ctx, logger)
+	var tasks []Task
+	for n := range geocodes {
+		name := n
+		tasks = append(tasks, Task{Key: name, Run: func() error {
+			g, err := FetchLocationCoordinates(geocoders, name, parts[name], ctx, logger)
 			if err != nil {
 				logger.Infof("Coordinates could not be fetched for location %s", name)
-				return
+				return err
 			}
-			logger.Infof("Fetched coordinates (%f, %f) for location %s", cs.Lat, cs.Lng, name)
+			logger.Infof("Fetched coordinates (%f, %f) for location %s from '%s'", g.Coordinates.Lat, g.Coordinates.Lng, name, g.Source)
 			
 			mutex.Lock()
-			coords[name] = &cs
+			geocodes[name] = &g
 			mutex.Unlock()
-		}(n)
-		count++
+			return nil
+		}})
+	}
+	
+	return RunTasks(tasks, geocodingConcurrency(geocoders))
+}
+
+// Error of a lookup whose result was one of the candidates that were rejected in review of the location name.
+var ErrRejectedInReview = errors.New("Rejected in review")
+
+// Negative cache entry for a failed lookup of a location name. Names that weren't found are retried after a period
+// that doubles with each consecutive failure (as are names whose result was rejected in review) while other failures
+// are retried soon.
+func NewGeocodeFailure(locName string, err error, previous *types.GeocodeFailure, now time.Time) types.GeocodeFailure {
+	notFoundHours, maxNotFoundHours, otherMinutes := config.NegativeCacheSettings()
+	
+	attempts := 1
+	if previous != nil {
+		attempts = previous.Attempts + 1
+	}
+	
+	var retryAfter time.Duration
+	if err == ErrAddressNotFound || err == ErrRejectedInReview {
+		hours := notFoundHours
+		for i := 1; i < attempts && hours < maxNotFoundHours; i++ {
+			hours *= 2
+		}
+		if hours > maxNotFoundHours {
+			hours = maxNotFoundHours
+		}
+		retryAfter = time.Duration(hours) * time.Hour
+	} else {
+		retryAfter = time.Duration(otherMinutes) * time.Minute
 	}
 	
-	// Wait for all go-routines to complete.
-	for i := 0; i < count; i++ {
-		<- ch
+	return types.GeocodeFailure{
+		LocationName: locName,
+		Reason:       err.Error(),
+		Attempts:     attempts,
+		FailedAt:     now,
+		RetryAt:      now.Add(retryAfter),
 	}
 }
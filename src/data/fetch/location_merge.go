@@ -0,0 +1,308 @@
+package fetch
+
+import (
+	"src/data/types"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Similarity scores at or above which variants of a location name are merged automatically or proposed for review.
+const StrongMergeScore = 0.9
+const WeakMergeScore = 0.75
+
+var cityParenthesizedRegex = regexp.MustCompile("(?i)\\(\\s*(?:san francisco|sf|s\\.f\\.)(?:,?\\s*ca)?\\s*\\)")
+var nonWordRegex = regexp.MustCompile("[^a-z0-9 ]+")
+var numberRegex = regexp.MustCompile("\\d+")
+
+var abbreviations = map[string]string{
+	"st":   "street",
+	"ave":  "avenue",
+	"av":   "avenue",
+	"blvd": "boulevard",
+	"dr":   "drive",
+	"pl":   "place",
+	"rd":   "road",
+	"ln":   "lane",
+	"ct":   "court",
+	"hwy":  "highway",
+	"sq":   "square",
+	"ctr":  "center",
+	"bldg": "building",
+}
+
+// Words that connect street names (e.g. "Lombard at Hyde" and "Lombard & Hyde") and carry no meaning of their own.
+var connectives = map[string]bool{
+	"and": true,
+	"at":  true,
+}
+
+var accents = strings.NewReplacer("é", "e", "è", "e", "á", "a", "à", "a", "í", "i", "ó", "o", "ú", "u", "ñ", "n")
+
+// Reduce location name to a canonical form where trivial variations like "The Golden Gate Bridge" and
+// "Golden Gate Bridge (San Francisco)" become identical.
+func NormalizeLocationName(name string) string {
+	str := cityParenthesizedRegex.ReplaceAllString(name, "")
+	str = accents.Replace(strings.ToLower(str))
+	str = strings.Replace(str, "&", " and ", -1)
+	str = strings.Replace(str, "'", "", -1)
+	str = nonWordRegex.ReplaceAllString(str, " ")
+	
+	words := strings.Fields(str)
+	if len(words) > 1 && words[0] == "the" {
+		words = words[1:]
+	}
+	var normalized []string
+	for _, w := range words {
+		if connectives[w] {
+			continue
+		}
+		if full, exists := abbreviations[w]; exists {
+			w = full
+		}
+		normalized = append(normalized, w)
+	}
+	return strings.Join(normalized, " ")
+}
+
+// Similarity in [0, 1] of two normalized location names as the average of their character and word similarity. Names
+// with different (street) numbers are never similar.
+func locationSimilarity(n1 string, n2 string) float64 {
+	if n1 == n2 {
+		return 1
+	}
+	if strings.Join(numberRegex.FindAllString(n1, -1), " ") != strings.Join(numberRegex.FindAllString(n2, -1), " ") {
+		return 0
+	}
+	
+	return (levenshteinRatio(n1, n2) + wordSimilarity(strings.Fields(n1), strings.Fields(n2))) / 2
+}
+
+func levenshteinRatio(s1 string, s2 string) float64 {
+	maxLen := len([]rune(s1))
+	if l := len([]rune(s2)); l > maxLen {
+		maxLen = l
+	}
+	if maxLen == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(s1, s2)) / float64(maxLen)
+}
+
+// Fraction of the words of both names that (approximately, to allow for typos) occur in the other name.
+func wordSimilarity(ws1 []string, ws2 []string) float64 {
+	if len(ws1) + len(ws2) == 0 {
+		return 1
+	}
+	return float64(countMatchingWords(ws1, ws2) + countMatchingWords(ws2, ws1)) / float64(len(ws1) + len(ws2))
+}
+
+func countMatchingWords(ws []string, others []string) int {
+	count := 0
+	for _, w := range ws {
+		for _, o := range others {
+			if w == o || levenshteinRatio(w, o) >= 0.8 {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+func levenshtein(s1 string, s2 string) int {
+	r1 := []rune(s1)
+	r2 := []rune(s2)
+	
+	prev := make([]int, len(r2) + 1)
+	curr := make([]int, len(r2) + 1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(r1); i++ {
+		curr[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i - 1] == r2[j - 1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j] + 1, minInt(curr[j - 1] + 1, prev[j - 1] + cost))
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(r2)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Words too common to indicate that two names refer to the same place.
+var stopWords = map[string]bool{
+	"and": true, "at": true, "of": true, "the": true, "in": true, "street": true, "avenue": true, "between": true,
+	"san": true, "francisco": true, "building": true, "park": true, "hotel": true, "restaurant": true,
+}
+
+// Propose merges of location name variants into canonical names. The names may contain duplicates; the most frequent
+// (and then shortest) variant of a group of strongly similar names is chosen as the canonical one. Strongly similar
+// names are proposed with the status `types.MergeMerged` and weakly similar ones with `types.MergePending`.
+func ProposeLocationMerges(names []string) []types.LocationMerge {
+	counts := make(map[string]int)
+	for _, name := range names {
+		counts[name]++
+	}
+	
+	distinct := make([]string, 0, len(counts))
+	for name := range counts {
+		distinct = append(distinct, name)
+	}
+	sort.Strings(distinct)
+	
+	normalized := make([]string, len(distinct))
+	for i, name := range distinct {
+		normalized[i] = NormalizeLocationName(name)
+	}
+	
+	// Only compare names that share a significant word.
+	wordIndices := make(map[string][]int)
+	for i, n := range normalized {
+		for _, w := range uniqueWords(n) {
+			if !stopWords[w] {
+				wordIndices[w] = append(wordIndices[w], i)
+			}
+		}
+	}
+	
+	compared := make(map[[2]int]bool)
+	var weakPairs []namePair
+	
+	// Union-find of strongly similar names.
+	parents := make([]int, len(distinct))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+	
+	for _, indices := range wordIndices {
+		for a := 0; a < len(indices); a++ {
+			for b := a + 1; b < len(indices); b++ {
+				i, j := indices[a], indices[b]
+				if compared[[2]int{i, j}] {
+					continue
+				}
+				compared[[2]int{i, j}] = true
+				
+				score := locationSimilarity(normalized[i], normalized[j])
+				if score >= StrongMergeScore {
+					parents[find(i)] = find(j)
+				} else if score >= WeakMergeScore {
+					weakPairs = append(weakPairs, namePair{i, j, score})
+				}
+			}
+		}
+	}
+	
+	// Pick canonical name of each group.
+	canonicals := make(map[int]int)
+	for i := range distinct {
+		root := find(i)
+		c, exists := canonicals[root]
+		if !exists || isBetterCanonical(distinct[i], distinct[c], counts) {
+			canonicals[root] = i
+		}
+	}
+	
+	var merges []types.LocationMerge
+	for i, name := range distinct {
+		c := canonicals[find(i)]
+		if c == i {
+			continue
+		}
+		merges = append(merges, types.LocationMerge{
+			Alias:     name,
+			Canonical: distinct[c],
+			Score:     locationSimilarity(normalized[i], normalized[c]),
+			Status:    types.MergeMerged,
+		})
+	}
+	
+	// Propose weak matches between different groups for review (once per alias, best match first).
+	sort.Sort(byScore(weakPairs))
+	proposed := make(map[string]bool)
+	for _, p := range weakPairs {
+		ci, cj := canonicals[find(p.i)], canonicals[find(p.j)]
+		if ci == cj {
+			continue
+		}
+		alias, canonical := distinct[ci], distinct[cj]
+		if isBetterCanonical(alias, canonical, counts) {
+			alias, canonical = canonical, alias
+		}
+		if proposed[alias] {
+			continue
+		}
+		proposed[alias] = true
+		merges = append(merges, types.LocationMerge{
+			Alias:     alias,
+			Canonical: canonical,
+			Score:     p.score,
+			Status:    types.MergePending,
+		})
+	}
+	
+	return merges
+}
+
+type namePair struct {
+	i, j  int
+	score float64
+}
+
+type byScore []namePair
+
+func (ps byScore) Len() int {
+	return len(ps)
+}
+func (ps byScore) Swap(i, j int) {
+	ps[i], ps[j] = ps[j], ps[i]
+}
+func (ps byScore) Less(i, j int) bool {
+	if ps[i].score != ps[j].score {
+		return ps[i].score > ps[j].score
+	}
+	if ps[i].i != ps[j].i {
+		return ps[i].i < ps[j].i
+	}
+	return ps[i].j < ps[j].j
+}
+
+func isBetterCanonical(name string, other string, counts map[string]int) bool {
+	if counts[name] != counts[other] {
+		return counts[name] > counts[other]
+	}
+	if len(name) != len(other) {
+		return len(name) < len(other)
+	}
+	return name < other
+}
+
+func uniqueWords(str string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, w := range strings.Fields(str) {
+		if !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	return words
+}
@@ -0,0 +1,268 @@
+package fetch
+
+import (
+	"src/data/types"
+	"regexp"
+	"strings"
+)
+
+// Location names in the data set are free text like "Epic Roasthouse (399 Embarcadero)",
+// "Mason & California Streets (Nob Hill)", or "Market St from 1st to 5th". The functions below extract the structured
+// parts that may be present in such names.
+
+var parenthesizedRegex = regexp.MustCompile("\\(([^)]*)(?:\\)|$)")
+var whitespaceRegex = regexp.MustCompile("\\s+")
+
+var streetSuffix = "(?:Street|Streets|St|Avenue|Ave|Boulevard|Blvd|Drive|Dr|Way|Place|Pl|Road|Rd|Lane|Ln|Alley|Terrace|Court|Ct|Plaza|Highway|Hwy|Embarcadero)\\.?"
+
+// "399 Embarcadero", "1337-1339 Grant Avenue", "1155 Filbert Street at Hyde".
+var addressRegex = regexp.MustCompile("(?i)^(\\d+)(?:-\\d+)?\\s+(\\S*[a-z].*?)(?:\\s+(?:at|near|&|and)\\s+(.+)|\\s+between\\s+.+)?$")
+
+// "200 block Market Street", "0-100 block of Halleck Street".
+var blockRegex = regexp.MustCompile("(?i)^(?:\\d+-)?(\\d+)\\s+block\\s+(?:of\\s+)?(.+)$")
+
+// "Café Picaro 3120 16th Street".
+var trailingAddressRegex = regexp.MustCompile("(?i)^(\\D+?)\\s+(\\d+(?:-\\d+)?\\s+\\S.*\\s" + streetSuffix + ")$")
+
+// "Market St from 1st to 5th", "Castro Street between 17th & 18th".
+var rangeRegex = regexp.MustCompile("(?i)^(.+?)\\s+(?:from|between)\\s+(.+?)\\s+(?:to|and|&)\\s+(.+)$")
+
+// "Mason & California Streets", "Intersection of Broadway at Kearney", "Mission Street @ 22nd Street".
+var intersectionRegex = regexp.MustCompile("(?i)^(?:intersection of\\s+)?(.+?)\\s+(?:&|and|at|@|/)\\s+(.+)$")
+
+var intersectionPrefixRegex = regexp.MustCompile("(?i)^intersection of\\s")
+var pluralStreetsRegex = regexp.MustCompile("(?i)\\s+Streets$")
+var hasStreetSuffixRegex = regexp.MustCompile("(?i)\\s" + streetSuffix + "$")
+
+// Streets that are commonly named without a suffix: "Broadway", "The Embarcadero", and numbered streets like "22nd".
+var suffixlessStreetRegex = regexp.MustCompile("(?i)^(?:Broadway|(?:The\\s+)?Embarcadero|\\d+(?:st|nd|rd|th))$")
+
+// "San Francisco", "CA 94132".
+var cityStateRegex = regexp.MustCompile("(?i)^(?:san francisco|sf|ca|california)?\\s*(?:ca\\s*)?(?:\\d{5})?$")
+
+// "Mission District", "Bernal Heights Neighborhood", "Nob Hill".
+var neighborhoodSuffixRegex = regexp.MustCompile("(?i)\\s+(?:District|Neighborhood)$")
+var neighborhoodRegex = regexp.MustCompile("(?i)^(?:the\\s+)?(?:.+\\s(?:District|Neighborhood|Hill|Valley|Heights|Square|Beach|Wharf|Park|Center)|Dogpatch|Presidio|Chinatown|Tenderloin|Marina|Castro|SoMa|Embarcadero|Mission)$")
+
+var knownNeighborhoods = map[string]bool{
+	"bayview":        true,
+	"castro":         true,
+	"chinatown":      true,
+	"civic center":   true,
+	"cow hollow":     true,
+	"dogpatch":       true,
+	"excelsior":      true,
+	"haight-ashbury": true,
+	"hayes valley":   true,
+	"japantown":      true,
+	"marina":         true,
+	"mission":        true,
+	"nob hill":       true,
+	"noe valley":     true,
+	"north beach":    true,
+	"presidio":       true,
+	"richmond":       true,
+	"soma":           true,
+	"sunset":         true,
+	"tenderloin":     true,
+	"twin peaks":     true,
+	"union square":   true,
+}
+
+func ParseLocationName(name string) types.LocationParts {
+	var parts types.LocationParts
+	
+	name = whitespaceRegex.ReplaceAllString(strings.TrimSpace(name), " ")
+	
+	// Split name into the part outside and inside of parentheses.
+	var inner []string
+	for _, match := range parenthesizedRegex.FindAllStringSubmatch(name, -1) {
+		inner = append(inner, match[1])
+	}
+	outer := strings.TrimSpace(parenthesizedRegex.ReplaceAllString(name, ""))
+	
+	// Parentheses usually contain the address or neighborhood of a landmark. Unclassified segments inside of them are
+	// ignored as they're usually comments like "now called ...".
+	for _, in := range inner {
+		for _, segment := range splitSegments(in) {
+			if !classifySegment(segment, &parts) && parts.Neighborhood == "" && isNeighborhood(segment) {
+				parts.Neighborhood = trimNeighborhood(segment)
+			}
+		}
+	}
+	
+	// The first unclassified segment outside of parentheses is the name of a landmark. If the parentheses contained an
+	// address or streets, everything outside of them is the landmark no matter what it looks like (e.g.
+	// "Foote, Cone & Belding").
+	if parts.Address != "" || parts.Intersection[0] != "" || parts.Range.Street != "" {
+		parts.Landmark = whitespaceRegex.ReplaceAllString(outer, " ")
+	} else {
+		for _, segment := range splitSegments(outer) {
+			if !classifySegment(segment, &parts) && parts.Landmark == "" {
+				if landmark, address := splitTrailingAddress(segment); address != "" {
+					parts.Landmark = landmark
+					classifySegment(address, &parts)
+				} else {
+					parts.Landmark = segment
+				}
+			}
+		}
+	}
+	
+	// A landmark that is really just a neighborhood.
+	if parts.Neighborhood == "" && isNeighborhood(parts.Landmark) && parts.Address == "" && parts.Intersection[0] == "" {
+		if neighborhoodSuffixRegex.MatchString(parts.Landmark) || knownNeighborhoods[strings.ToLower(parts.Landmark)] {
+			parts.Neighborhood = trimNeighborhood(parts.Landmark)
+			parts.Landmark = ""
+		}
+	}
+	
+	return parts
+}
+
+func splitSegments(str string) []string {
+	var segments []string
+	for _, s := range strings.Split(str, ",") {
+		s = strings.Trim(strings.TrimSpace(s), "_")
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// Classify segment as an address, street range, intersection, or neighborhood and store it in the first unset
+// matching part. Returns false if the segment couldn't be classified.
+func classifySegment(segment string, parts *types.LocationParts) bool {
+	if cityStateRegex.MatchString(segment) {
+		// Redundant city or state/ZIP code; consider it classified.
+		return true
+	}
+	
+	if match := blockRegex.FindStringSubmatch(segment); match != nil {
+		if parts.Address == "" {
+			parts.Address = match[1] + " " + match[2]
+		}
+		return true
+	}
+	
+	if match := addressRegex.FindStringSubmatch(segment); match != nil {
+		if parts.Address == "" {
+			parts.Address = match[1] + " " + match[2]
+		}
+		if crossStreet := match[3]; crossStreet != "" && parts.Intersection[0] == "" {
+			parts.Intersection = streets(match[2], crossStreet)
+		}
+		return true
+	}
+	
+	if match := rangeRegex.FindStringSubmatch(segment); match != nil && looksLikeStreet(match[1]) {
+		if parts.Range.Street == "" {
+			from, to := match[2], match[3]
+			if pluralStreetsRegex.MatchString(to) {
+				cs := streets(from, to)
+				from, to = cs[0], cs[1]
+			}
+			parts.Range = types.StreetRange{Street: match[1], From: from, To: to}
+		}
+		return true
+	}
+	
+	if match := intersectionRegex.FindStringSubmatch(segment); match != nil && isIntersection(segment, match[1], match[2]) {
+		if parts.Intersection[0] == "" {
+			parts.Intersection = streets(match[1], match[2])
+		}
+		return true
+	}
+	
+	if knownNeighborhoods[strings.ToLower(trimNeighborhood(segment))] || neighborhoodSuffixRegex.MatchString(segment) {
+		if parts.Neighborhood == "" {
+			parts.Neighborhood = trimNeighborhood(segment)
+		}
+		return true
+	}
+	
+	return false
+}
+
+// Split "Café Picaro 3120 16th Street" into a landmark and an address.
+func splitTrailingAddress(segment string) (string, string) {
+	match := trailingAddressRegex.FindStringSubmatch(segment)
+	if match == nil {
+		return segment, ""
+	}
+	return match[1], match[2]
+}
+
+// Pair of streets where a trailing "Streets" (as in "Mason & California Streets") applies to both.
+func streets(s1 string, s2 string) [2]string {
+	s1 = strings.TrimSpace(s1)
+	s2 = strings.TrimSpace(s2)
+	if pluralStreetsRegex.MatchString(s2) {
+		s2 = pluralStreetsRegex.ReplaceAllString(s2, " Street")
+		if !hasStreetSuffixRegex.MatchString(s1) {
+			s1 += " Street"
+		}
+	}
+	return [2]string{s1, s2}
+}
+
+// Streets have short names and no possessives (which rules out e.g. "St. Peter & Paul's Church").
+func looksLikeStreet(str string) bool {
+	return str != "" && !strings.ContainsAny(str, "'’") && len(strings.Fields(str)) <= 4
+}
+
+// Whether a street name has a suffix (like "Street" or "Ave") or is commonly named without one.
+func isStreetName(str string) bool {
+	return looksLikeStreet(str) && (hasStreetSuffixRegex.MatchString(str) || suffixlessStreetRegex.MatchString(str))
+}
+
+// Whether the two sides of a segment like "X & Y" are streets. As the pattern also matches names like "Sutro Baths and
+// Cliff House", both sides must be street names unless the segment says it's an intersection or a trailing "Streets"
+// applies to both sides (as in "Mason & California Streets").
+func isIntersection(segment string, s1 string, s2 string) bool {
+	if intersectionPrefixRegex.MatchString(segment) {
+		return looksLikeStreet(s1) && looksLikeStreet(s2)
+	}
+	if pluralStreetsRegex.MatchString(s2) {
+		return looksLikeStreet(s1) && isStreetName(s2)
+	}
+	return isStreetName(s1) && isStreetName(s2)
+}
+
+func isNeighborhood(str string) bool {
+	return str != "" && (knownNeighborhoods[strings.ToLower(trimNeighborhood(str))] || neighborhoodRegex.MatchString(str))
+}
+
+func trimNeighborhood(str string) string {
+	return strings.TrimSpace(neighborhoodSuffixRegex.ReplaceAllString(str, ""))
+}
+
+// Queries to try (in order) when geocoding a location with the given name.
+func geocodingQueries(name string, parts types.LocationParts) []string {
+	var queries []string
+	add := func(q string) {
+		q = strings.TrimSpace(q)
+		if q == "" {
+			return
+		}
+		for _, existing := range queries {
+			if strings.EqualFold(existing, q) {
+				return
+			}
+		}
+		queries = append(queries, q)
+	}
+	
+	add(parts.Address)
+	if parts.Intersection[0] != "" {
+		add(parts.Intersection[0] + " & " + parts.Intersection[1])
+	}
+	add(name)
+	add(parts.Landmark)
+	if parts.Range.Street != "" {
+		add(parts.Range.Street + " & " + parts.Range.From)
+	}
+	add(parts.Neighborhood)
+	return queries
+}
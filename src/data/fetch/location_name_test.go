@@ -0,0 +1,59 @@
+package fetch
+
+import (
+	"src/data/types"
+	"testing"
+)
+
+func TestParseLocationName(t *testing.T) {
+	tests := []struct {
+		name string
+		want types.LocationParts
+	}{
+		{"City Hall", types.LocationParts{Landmark: "City Hall"}},
+		{"Epic Roasthouse (399 Embarcadero)", types.LocationParts{Landmark: "Epic Roasthouse", Address: "399 Embarcadero"}},
+		{"Café Picaro 3120 16th Street", types.LocationParts{Landmark: "Café Picaro", Address: "3120 16th Street"}},
+		{"1155 Filbert Street at Hyde", types.LocationParts{Address: "1155 Filbert Street", Intersection: [2]string{"Filbert Street", "Hyde"}}},
+		{"200 block Market Street", types.LocationParts{Address: "200 Market Street"}},
+		{"Mason & California Streets (Nob Hill)", types.LocationParts{Intersection: [2]string{"Mason Street", "California Street"}, Neighborhood: "Nob Hill"}},
+		{"Mission Street @ 22nd Street", types.LocationParts{Intersection: [2]string{"Mission Street", "22nd Street"}}},
+		{"Broadway & Columbus Ave", types.LocationParts{Intersection: [2]string{"Broadway", "Columbus Ave"}}},
+		{"Intersection of Broadway at Kearney", types.LocationParts{Intersection: [2]string{"Broadway", "Kearney"}}},
+		{"Market St from 1st to 5th", types.LocationParts{Range: types.StreetRange{Street: "Market St", From: "1st", To: "5th"}}},
+		{"Bernal Heights Neighborhood", types.LocationParts{Neighborhood: "Bernal Heights"}},
+		
+		// Landmarks that look like intersections.
+		{"Sutro Baths and Cliff House", types.LocationParts{Landmark: "Sutro Baths and Cliff House"}},
+		{"Palace of Fine Arts & Lagoon", types.LocationParts{Landmark: "Palace of Fine Arts & Lagoon"}},
+		{"St. Peter & Paul's Church", types.LocationParts{Landmark: "St. Peter & Paul's Church"}},
+		{"Golden Gate Park at Stow Lake", types.LocationParts{Landmark: "Golden Gate Park at Stow Lake"}},
+	}
+	for _, test := range tests {
+		if got := ParseLocationName(test.name); got != test.want {
+			t.Errorf("ParseLocationName(%q) = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestIsIntersection(t *testing.T) {
+	tests := []struct {
+		segment string
+		s1      string
+		s2      string
+		want    bool
+	}{
+		{"Mason & California Streets", "Mason", "California Streets", true},
+		{"Mission Street @ 22nd Street", "Mission Street", "22nd Street", true},
+		{"Van Ness Ave and Geary Blvd", "Van Ness Ave", "Geary Blvd", true},
+		{"The Embarcadero at Broadway", "The Embarcadero", "Broadway", true},
+		{"Intersection of Broadway at Kearney", "Broadway", "Kearney", true},
+		{"Sutro Baths and Cliff House", "Sutro Baths", "Cliff House", false},
+		{"Mission Street & Dolores Park", "Mission Street", "Dolores Park", false},
+		{"Coit Tower & Lombard Street", "Coit Tower", "Lombard Street", false},
+	}
+	for _, test := range tests {
+		if got := isIntersection(test.segment, test.s1, test.s2); got != test.want {
+			t.Errorf("isIntersection(%q, %q, %q) = %v, want %v", test.segment, test.s1, test.s2, got, test.want)
+		}
+	}
+}
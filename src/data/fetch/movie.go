@@ -4,15 +4,14 @@ import (
 	"src/data/types"
 	"src/logging"
 	"appengine"
-	"appengine/urlfetch"
-	"io/ioutil"
 	"encoding/json"
 	"strconv"
-	"log"
 	"strings"
+	"fmt"
 )
 
-type entry struct {
+// Row of the data set.
+type Entry struct {
 	Actor_1            string
 	Actor_2            string
 	Actor_3            string
@@ -24,89 +23,190 @@ type entry struct {
 	Release_year       string
 	Title              string
 	Writer             string
+	
+	// Index of the entry in the data set.
+	Row                int `json:"-"`
+	
+	// Parts of the location name (added by the "parse-location-name" rule).
+	Parts              types.LocationParts `json:"-"`
 }
 
-func FetchFromUrl(url string, ctx appengine.Context, log logging.Logger) ([]types.Movie, error) {
-	log.Infof("Fetching from URL '%s'", url)
-	bytes, err := fetchBytes(url, ctx)
-	if err != nil {
-		return nil, err
-	}
-	log.Infof("Fetched %d bytes", len(bytes))
-	entries, err := fetchEntries(bytes)
-	if err != nil {
-		return nil, err
+type field struct {
+	name  string
+	value *string
+}
+
+// Text fields of the entry with the names that they have in the data set.
+func (e *Entry) fields() []field {
+	return []field{
+		{"actor_1", &e.Actor_1},
+		{"actor_2", &e.Actor_2},
+		{"actor_3", &e.Actor_3},
+		{"director", &e.Director},
+		{"locations", &e.Locations},
+		{"fun_facts", &e.Fun_facts},
+		{"production_company", &e.Production_company},
+		{"release_year", &e.Release_year},
+		{"title", &e.Title},
+		{"writer", &e.Writer},
 	}
-	log.Infof("Resolved %d entries", len(entries))
-	movies := entriesToMovies(entries)
-	log.Infof("Resolved %d movies", len(movies))
-	return movies, nil
 }
 
-func FetchFromFile(fileName string) ([]types.Movie, error) {
-	log.Println("Fetching from file: " + fileName)
-	bytes, err := ioutil.ReadFile(fileName)
-	if err != nil {
+func ReadEntries(bytes []byte) ([]Entry, error) {
+	var entries []Entry
+	if err := json.Unmarshal(bytes, &entries); err != nil {
 		return nil, err
 	}
-	
-	entries, err := fetchEntries(bytes)
-	if err != nil {
-		return nil, err
+	for i := range entries {
+		entries[i].Row = i
 	}
-	
-	return entriesToMovies(entries), nil
-}
-
-func fetchEntries(bytes []byte) (entries []entry, err error) {
-	err = json.Unmarshal(bytes, &entries)
-	return
+	return entries, nil
 }
 
-func fetchBytes(url string, ctx appengine.Context) ([]byte, error) {
-	client := urlfetch.Client(ctx)
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	
-	return ioutil.ReadAll(resp.Body)
+func FetchBytes(url string, ctx appengine.Context, log logging.Logger) ([]byte, error) {
+	return Get(url, SocrataLimiter, ctx, log)
 }
 
-func entriesToMovies(entries []entry) []types.Movie {
-	// Read entries into map indexed by the movie title.
-	titleMovieMap := make(map[string]*types.Movie)
+// Group entries into movies. Disagreements between entries are reported as conflicts.
+func EntriesToMovies(entries []Entry, report *ValidationReport) []types.Movie {
+	// Read entries into map indexed by the identity of the movie (title, release year, and director).
+	keyMovieMap := make(map[types.MovieKey]*types.Movie)
+	keyRowsMap := make(map[types.MovieKey][]int)
+	var keys []types.MovieKey
+	conflicts := &rowConflicts{index: make(map[rowConflictKey]int)}
 	for _, entry := range entries {
-		// Parse location data and skip entry if it's empty.
+		// Parse location data and skip entry if it's empty (validated entries never are).
 		loc := entryToLocation(entry)
 		if loc.Name == "" {
 			continue
 		}
 		
 		// Allocate new movie entry if it doesn't exist.
-		title := entry.Title
-		movie, exists := titleMovieMap[title]
+		m := entryToMovie(entry)
+		key := m.Key()
+		movie, exists := keyMovieMap[key]
 		if !exists {
-			m := entryToMovie(entry)
 			movie = &m
-			titleMovieMap[title] = movie;
+			keyMovieMap[key] = movie
+			keys = append(keys, key)
+		} else {
+			conflicts.add(movie, &m, keyRowsMap[key][0], entry.Row)
 		}
+		keyRowsMap[key] = append(keyRowsMap[key], entry.Row)
 		
 		// Add location to entry.
 		movie.Locations = append(movie.Locations, loc)
 	}
 	
+	conflicts.report(report)
+	reportSharedTitles(keys, keyRowsMap, report)
+	
 	// Extract map values to slice...
-	movies := make([]types.Movie, 0, len(titleMovieMap))
-	for _, movie := range titleMovieMap {
-		movies = append(movies, *movie)
+	movies := make([]types.Movie, 0, len(keyMovieMap))
+	for _, key := range keys {
+		movies = append(movies, *keyMovieMap[key])
 	}
 	
 	return movies
 }
 
-func entryToMovie(entry entry) (movie types.Movie) {
+type rowConflictKey struct {
+	movie types.MovieKey
+	field string
+}
+
+// Disagreement of the rows of a movie on a field with the distinct values of the field.
+type rowConflict struct {
+	title  string
+	field  string
+	values []string
+	rows   []int
+}
+
+// Conflicts of rows collected per movie and field such that each one is reported once (no matter how many rows
+// disagree).
+type rowConflicts struct {
+	index     map[rowConflictKey]int
+	conflicts []rowConflict
+}
+
+// Record the fields that aren't part of the identity of the movie on which a row disagrees with the first row.
+func (cs *rowConflicts) add(movie *types.Movie, m *types.Movie, firstRow int, row int) {
+	if movie.Writer != m.Writer {
+		cs.record(movie, "Writer", movie.Writer, m.Writer, firstRow, row)
+	}
+	if movie.ProductionCompany != m.ProductionCompany {
+		cs.record(movie, "Production company", movie.ProductionCompany, m.ProductionCompany, firstRow, row)
+	}
+	if a1, a2 := strings.Join(movie.Actors, ", "), strings.Join(m.Actors, ", "); a1 != a2 {
+		cs.record(movie, "Actors", a1, a2, firstRow, row)
+	}
+}
+
+func (cs *rowConflicts) record(movie *types.Movie, field string, firstValue string, value string, firstRow int, row int) {
+	key := rowConflictKey{movie.Key(), field}
+	i, exists := cs.index[key]
+	if !exists {
+		i = len(cs.conflicts)
+		cs.index[key] = i
+		cs.conflicts = append(cs.conflicts, rowConflict{title: movie.Title, field: field, values: []string{firstValue}, rows: []int{firstRow}})
+	}
+	c := &cs.conflicts[i]
+	c.values = addValue(c.values, value)
+	c.rows = append(c.rows, row)
+}
+
+func addValue(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// Report the conflicts of rows of the same movie.
+func (cs *rowConflicts) report(report *ValidationReport) {
+	if report == nil {
+		return
+	}
+	for _, c := range cs.conflicts {
+		report.addConflict(c.title, c.rows, "%s differs between rows: %s", c.field, quoteNames(c.values))
+	}
+}
+
+// Report titles that are shared by several movies, such that they may be reviewed.
+func reportSharedTitles(keys []types.MovieKey, keyRowsMap map[types.MovieKey][]int, report *ValidationReport) {
+	if report == nil {
+		return
+	}
+	
+	titleKeysMap := make(map[string][]types.MovieKey)
+	var titles []string
+	for _, key := range keys {
+		if _, exists := titleKeysMap[key.Title]; !exists {
+			titles = append(titles, key.Title)
+		}
+		titleKeysMap[key.Title] = append(titleKeysMap[key.Title], key)
+	}
+	
+	for _, title := range titles {
+		titleKeys := titleKeysMap[title]
+		if len(titleKeys) < 2 {
+			continue
+		}
+		
+		var rows []int
+		var descs []string
+		for _, key := range titleKeys {
+			rows = append(rows, keyRowsMap[key]...)
+			descs = append(descs, fmt.Sprintf("%d by '%s'", key.ReleaseYear, key.Director))
+		}
+		report.addConflict(title, rows, "Title is shared by %d movies: %s", len(titleKeys), strings.Join(descs, "; "))
+	}
+}
+
+func entryToMovie(entry Entry) (movie types.Movie) {
 	// "Location"/"Fun fact" is added in `entryToLocation` below.
 	movie.Title = cleaned(entry.Title)
 	
@@ -115,13 +215,13 @@ func entryToMovie(entry entry) (movie types.Movie) {
 	cleanedActor3 := cleaned(entry.Actor_3)
 	
 	if cleanedActor1 != "" {
-		movie.Actors = append(movie.Actors, entry.Actor_1)
+		movie.Actors = append(movie.Actors, cleanedActor1)
 	}
 	if cleanedActor2 != "" {
-		movie.Actors = append(movie.Actors, entry.Actor_2)
+		movie.Actors = append(movie.Actors, cleanedActor2)
 	}
 	if cleanedActor3 != "" {
-		movie.Actors = append(movie.Actors, entry.Actor_3)
+		movie.Actors = append(movie.Actors, cleanedActor3)
 	}
 	
 	movie.Director = cleaned(entry.Director)
@@ -137,9 +237,10 @@ func entryToMovie(entry entry) (movie types.Movie) {
 	return
 }
 
-func entryToLocation(entry entry) (loc types.Location) {
+func entryToLocation(entry Entry) (loc types.Location) {
 	loc.Name = cleaned(entry.Locations)
 	loc.FunFact = cleaned(entry.Fun_facts)
+	loc.Parts = entry.Parts
 	return
 }
 
@@ -1,43 +1,472 @@
 package fetch
 
 import (
+	"src/config"
+	"src/data/types"
 	"src/logging"
-	"src/watch"
 	"appengine"
-	"appengine/urlfetch"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 )
 
-func FetchMovieInfo(title string, ctx appengine.Context, log logging.Logger) (string, error) {
-	// Sanitize movie title.
-	// TODO Should cache compiled regex.
-	regex, err := regexp.Compile("(?i)\\s*(-|,|season).*")
+var ErrMovieNotFound = errors.New("Movie not found")
+
+// Service looking up the metadata of a movie: The movie with the IMDb ID `imdbId` if it's set (as pinned by an admin)
+// and otherwise the best match found by searching for the movie. Returns `ErrMovieNotFound` if the movie has no match.
+// The result is normalized into the provider-independent `types.MovieMetadata`.
+type MetadataProvider interface {
+	Name() string
+	FetchMetadata(movie types.Movie, imdbId string, ctx appengine.Context, log logging.Logger) (types.MovieMetadata, error)
+}
+
+// Provider selected by configuration.
+func NewMetadataProvider() MetadataProvider {
+	name, omdbBaseUrl, tmdbBaseUrl, fixtureFileName := config.MetadataSettings()
+	switch name {
+	case "omdb":
+		return &OmdbProvider{BaseUrl: omdbBaseUrl, ApiKey: config.OmdbApiKey()}
+	case "tmdb":
+		return &TmdbProvider{BaseUrl: tmdbBaseUrl, ApiKey: config.TmdbApiKey()}
+	case "fixture":
+		return &FixtureMetadataProvider{FileName: fixtureFileName}
+	}
+	panic(fmt.Sprintf("Unknown metadata provider '%s'", name))
+}
+
+// Provider using the OMDB API. The API key is optional for self-hosted compatible services.
+type OmdbProvider struct {
+	BaseUrl string
+	ApiKey  string
+}
+
+// Result of searching the OMDB API.
+type omdbSearch struct {
+	Response string
+	Search   []struct {
+		Title  string
+		Year   string
+		ImdbID string `json:"imdbID"`
+	}
+}
+
+// Response of the OMDB API. Unknown values are given as "N/A".
+type omdbMovie struct {
+	Response   string
+	Title      string
+	Year       string
+	Rated      string
+	Released   string
+	Runtime    string
+	Genre      string
+	Director   string
+	Writer     string
+	Actors     string
+	Plot       string
+	Language   string
+	Country    string
+	Awards     string
+	Poster     string
+	Metascore  string
+	ImdbRating string `json:"imdbRating"`
+	ImdbVotes  string `json:"imdbVotes"`
+	ImdbID     string `json:"imdbID"`
+}
+
+func (p *OmdbProvider) Name() string {
+	return "omdb"
+}
+
+func (p *OmdbProvider) get(params url.Values, v interface{}, ctx appengine.Context, log logging.Logger) error {
+	params.Set("r", "json")
+	uri := strings.TrimRight(p.BaseUrl, "/") + "/?" + params.Encode()
+	log.Infof("Fetching movie metadata from URL '%s'", uri)
+	if p.ApiKey != "" {
+		uri += "&apikey=" + url.QueryEscape(p.ApiKey)
+	}
+	bytes, err := Get(uri, OmdbLimiter, ctx, log)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	return json.Unmarshal(bytes, v)
+}
+
+func (p *OmdbProvider) fetchDetails(imdbId string, ctx appengine.Context, log logging.Logger) (types.MovieMetadata, error) {
+	var res omdbMovie
+	if err := p.get(url.Values{"i": {imdbId}, "plot": {"short"}}, &res, ctx, log); err != nil {
+		return types.MovieMetadata{}, err
+	}
+	if res.Response != "True" {
+		return types.MovieMetadata{}, ErrMovieNotFound
+	}
+	return res.metadata(), nil
+}
+
+// Search candidates by each of the search titles of the movie until some are found: First in the release year of the
+// movie and then (as years may differ between the data set and OMDB) in any year.
+func (p *OmdbProvider) search(movie types.Movie, ctx appengine.Context, log logging.Logger) ([]movieCandidate, error) {
+	var years []string
+	if movie.ReleaseYear != 0 {
+		years = append(years, strconv.Itoa(movie.ReleaseYear))
+	}
+	years = append(years, "")
 	
-	sanitizedTitle := regex.ReplaceAllString(title, "")
-	
-	uri := "http://www.omdbapi.com/?y=&plot=short&r=json&t=" + url.QueryEscape(sanitizedTitle)
-	
-	sw := watch.NewStopWatch()
+	for _, title := range searchTitles(movie.Title) {
+		for _, year := range years {
+			params := url.Values{"s": {title}}
+			if year != "" {
+				params.Set("y", year)
+			}
+			var res omdbSearch
+			if err := p.get(params, &res, ctx, log); err != nil {
+				return nil, err
+			}
+			if res.Response != "True" {
+				continue
+			}
+			
+			candidates := make([]movieCandidate, len(res.Search))
+			for i, r := range res.Search {
+				candidates[i] = movieCandidate{Id: r.ImdbID, Title: r.Title, Year: leadingInt(r.Year)}
+			}
+			return candidates, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p *OmdbProvider) FetchMetadata(movie types.Movie, imdbId string, ctx appengine.Context, log logging.Logger) (types.MovieMetadata, error) {
+	if imdbId != "" {
+		log.Infof("Fetching info for movie '%s' by pinned IMDb ID %s", movie.Title, imdbId)
+		metadata, err := p.fetchDetails(imdbId, ctx, log)
+		metadata.MatchConfidence = 1
+		return metadata, err
+	}
 	
-	log.Infof("Fetching info for movie '%s' ('%s') from URL '%s'", title, sanitizedTitle, uri)
-	client := urlfetch.Client(ctx)
-	resp, err := client.Get(uri)
+	log.Infof("Searching info for movie '%s' (%d)", movie.Title, movie.ReleaseYear)
+	candidates, err := p.search(movie, ctx, log)
 	if err != nil {
-		return "", err
+		return types.MovieMetadata{}, err
+	}
+	return bestMatch(movie, candidates, func (id string) (types.MovieMetadata, error) {
+		return p.fetchDetails(id, ctx, log)
+	}, log)
+}
+
+// Normalize a raw response of the OMDB API (as cached by earlier versions). Returns nil if the movie wasn't found.
+func ParseOmdbMovie(infoJson string) (*types.MovieMetadata, error) {
+	if infoJson == "" {
+		return nil, nil
+	}
+	var res omdbMovie
+	if err := json.Unmarshal([]byte(infoJson), &res); err != nil {
+		return nil, err
+	}
+	if res.Response != "True" {
+		return nil, nil
+	}
+	metadata := res.metadata()
+	return &metadata, nil
+}
+
+func (m *omdbMovie) metadata() types.MovieMetadata {
+	return types.MovieMetadata{
+		Provider:       "omdb",
+		ProviderId:     omdbValue(m.ImdbID),
+		ImdbId:         omdbValue(m.ImdbID),
+		Title:          omdbValue(m.Title),
+		Year:           leadingInt(omdbValue(m.Year)),
+		Released:       omdbValue(m.Released),
+		RuntimeMinutes: leadingInt(omdbValue(m.Runtime)),
+		Rated:          omdbValue(m.Rated),
+		Genres:         omdbList(m.Genre),
+		Directors:      omdbList(m.Director),
+		Writers:        omdbList(m.Writer),
+		Actors:         omdbList(m.Actors),
+		Plot:           omdbValue(m.Plot),
+		Languages:      omdbList(m.Language),
+		Countries:      omdbList(m.Country),
+		Awards:         omdbValue(m.Awards),
+		PosterUrl:      omdbValue(m.Poster),
+		Metascore:      leadingInt(omdbValue(m.Metascore)),
+		ImdbRating:     float32(leadingFloat(omdbValue(m.ImdbRating))),
+		ImdbVotes:      leadingInt(strings.Replace(omdbValue(m.ImdbVotes), ",", "", -1)),
+	}
+}
+
+func omdbValue(value string) string {
+	if value == "N/A" {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+// Comma separated list of values (like "Drama, Thriller").
+func omdbList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(omdbValue(value), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+var leadingNumberRegex = regexp.MustCompile("^\\d+(\\.\\d+)?")
+
+// Integer at the start of a value like "1958" or "128 min" (0 if there is none).
+func leadingInt(value string) int {
+	i, _ := strconv.Atoi(strings.SplitN(leadingNumberRegex.FindString(value), ".", 2)[0])
+	return i
+}
+
+func leadingFloat(value string) float64 {
+	f, _ := strconv.ParseFloat(leadingNumberRegex.FindString(value), 64)
+	return f
+}
+
+// Provider using the TMDB API (or a compatible service). The movie is searched by title and release year and the details
+// (including credits) of the best candidates are then fetched.
+type TmdbProvider struct {
+	BaseUrl string
+	ApiKey  string
+}
+
+// Base URL of the posters referred to by path.
+const tmdbPosterBaseUrl = "https://image.tmdb.org/t/p/w342"
+
+type tmdbName struct {
+	Name        string
+	EnglishName string `json:"english_name"`
+}
+
+type tmdbMovie struct {
+	Id                  int
+	ImdbId              string `json:"imdb_id"`
+	Title               string
+	ReleaseDate         string `json:"release_date"`
+	Runtime             int
+	Overview            string
+	PosterPath          string `json:"poster_path"`
+	Genres              []tmdbName
+	SpokenLanguages     []tmdbName `json:"spoken_languages"`
+	ProductionCountries []tmdbName `json:"production_countries"`
+	Credits             struct {
+		Cast []struct { Name string }
+		Crew []struct { Name, Job string }
+	}
+}
+
+func (p *TmdbProvider) Name() string {
+	return "tmdb"
+}
+
+func (p *TmdbProvider) get(path string, params url.Values, v interface{}, ctx appengine.Context, log logging.Logger) error {
+	uri := strings.TrimRight(p.BaseUrl, "/") + path + "?" + params.Encode()
+	log.Infof("Fetching movie metadata from URL '%s'", uri)
+	uri += "&api_key=" + url.QueryEscape(p.ApiKey)
+	bytes, err := Get(uri, TmdbLimiter, ctx, log)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+func (p *TmdbProvider) fetchDetails(id string, ctx appengine.Context, log logging.Logger) (types.MovieMetadata, error) {
+	var res tmdbMovie
+	if err := p.get("/movie/" + id, url.Values{"append_to_response": {"credits"}}, &res, ctx, log); err != nil {
+		return types.MovieMetadata{}, err
+	}
+	if res.Id == 0 {
+		return types.MovieMetadata{}, ErrMovieNotFound
+	}
+	return res.metadata(), nil
+}
+
+func (p *TmdbProvider) FetchMetadata(movie types.Movie, imdbId string, ctx appengine.Context, log logging.Logger) (types.MovieMetadata, error) {
+	if imdbId != "" {
+		var found struct {
+			MovieResults []struct { Id int } `json:"movie_results"`
+		}
+		if err := p.get("/find/" + imdbId, url.Values{"external_source": {"imdb_id"}}, &found, ctx, log); err != nil {
+			return types.MovieMetadata{}, err
+		}
+		if len(found.MovieResults) == 0 {
+			return types.MovieMetadata{}, ErrMovieNotFound
+		}
+		metadata, err := p.fetchDetails(strconv.Itoa(found.MovieResults[0].Id), ctx, log)
+		metadata.MatchConfidence = 1
+		return metadata, err
 	}
-	defer resp.Body.Close()
 	
-	bytes, err := ioutil.ReadAll(resp.Body)
+	var candidates []movieCandidate
+	for _, title := range searchTitles(movie.Title) {
+		params := url.Values{"query": {title}}
+		if movie.ReleaseYear != 0 {
+			params.Set("year", strconv.Itoa(movie.ReleaseYear))
+		}
+		var search struct {
+			Results []struct {
+				Id          int
+				Title       string
+				ReleaseDate string `json:"release_date"`
+			}
+		}
+		if err := p.get("/search/movie", params, &search, ctx, log); err != nil {
+			return types.MovieMetadata{}, err
+		}
+		for _, r := range search.Results {
+			candidates = append(candidates, movieCandidate{Id: strconv.Itoa(r.Id), Title: r.Title, Year: leadingInt(r.ReleaseDate)})
+		}
+		if len(candidates) > 0 {
+			break
+		}
+	}
+	return bestMatch(movie, candidates, func (id string) (types.MovieMetadata, error) {
+		return p.fetchDetails(id, ctx, log)
+	}, log)
+}
+
+// Number of cast members included as actors.
+const tmdbMaxActors = 5
+
+func (m *tmdbMovie) metadata() types.MovieMetadata {
+	metadata := types.MovieMetadata{
+		Provider:       "tmdb",
+		ProviderId:     strconv.Itoa(m.Id),
+		ImdbId:         m.ImdbId,
+		Title:          m.Title,
+		Year:           leadingInt(m.ReleaseDate),
+		Released:       m.ReleaseDate,
+		RuntimeMinutes: m.Runtime,
+		Plot:           m.Overview,
+	}
+	if m.PosterPath != "" {
+		metadata.PosterUrl = tmdbPosterBaseUrl + m.PosterPath
+	}
+	for _, g := range m.Genres {
+		metadata.Genres = append(metadata.Genres, g.Name)
+	}
+	for _, l := range m.SpokenLanguages {
+		name := l.EnglishName
+		if name == "" {
+			name = l.Name
+		}
+		metadata.Languages = append(metadata.Languages, name)
+	}
+	for _, c := range m.ProductionCountries {
+		metadata.Countries = append(metadata.Countries, c.Name)
+	}
+	for i, c := range m.Credits.Cast {
+		if i == tmdbMaxActors {
+			break
+		}
+		metadata.Actors = append(metadata.Actors, c.Name)
+	}
+	for _, c := range m.Credits.Crew {
+		switch c.Job {
+		case "Director":
+			metadata.Directors = append(metadata.Directors, c.Name)
+		case "Screenplay", "Writer", "Story", "Novel":
+			metadata.Writers = append(metadata.Writers, c.Name)
+		}
+	}
+	return metadata
+}
+
+// Provider looking up movies by title (case-insensitively) in a JSON file mapping titles to metadata like
+// `{"Vertigo": {"Year": 1958, "Genres": ["Mystery"]}}`. Intended for development and tests without network access.
+type FixtureMetadataProvider struct {
+	FileName string
+	once     sync.Once
+	movies   map[string]types.MovieMetadata
+	err      error
+}
+
+func (p *FixtureMetadataProvider) Name() string {
+	return "fixture"
+}
+
+func (p *FixtureMetadataProvider) load() {
+	bytes, err := ioutil.ReadFile(p.FileName)
 	if err != nil {
-		return "", err
+		p.err = err
+		return
+	}
+	var movies map[string]types.MovieMetadata
+	if err := json.Unmarshal(bytes, &movies); err != nil {
+		p.err = err
+		return
 	}
+	p.movies = make(map[string]types.MovieMetadata)
+	for title, m := range movies {
+		p.movies[strings.ToLower(title)] = m
+	}
+}
+
+func (p *FixtureMetadataProvider) FetchMetadata(movie types.Movie, imdbId string, ctx appengine.Context, log logging.Logger) (types.MovieMetadata, error) {
+	p.once.Do(p.load)
+	if p.err != nil {
+		return types.MovieMetadata{}, p.err
+	}
+	
+	m, exists := p.movies[strings.ToLower(movie.Title)]
+	if imdbId != "" {
+		exists = false
+		for _, fm := range p.movies {
+			if fm.ImdbId == imdbId {
+				m, exists = fm, true
+				break
+			}
+		}
+	}
+	if !exists {
+		return types.MovieMetadata{}, ErrMovieNotFound
+	}
+	m.Provider = "fixture"
+	if m.Title == "" {
+		m.Title = movie.Title
+	}
+	if imdbId != "" {
+		m.MatchConfidence = 1
+	} else {
+		m.MatchConfidence = matchScore(movie, m.Title, m.Year, m.Directors)
+	}
+	return m, nil
+}
+
+// Fetch the metadata of the movies concurrently (by their pinned IMDb IDs if they have any). Movies that weren't found
+// map to nil. Movies whose metadata is missing from the result had their metadata fetched unsuccessfully (as given by
+// the returned task results).
+func FetchMoviesMetadata(provider MetadataProvider, movies []types.Movie, pins map[types.MovieKey]string, ctx appengine.Context, log logging.Logger) (map[types.MovieKey]*types.MovieMetadata, TaskResults) {
+	mutex := &sync.Mutex{}
+	movieKeyMetadata := make(map[types.MovieKey]*types.MovieMetadata)
 	
-	log.Infof("Fetched %d bytes in %d ms", len(bytes), sw.TotalElapsedTimeMillis())
+	tasks := make([]Task, len(movies))
+	for i := range movies {
+		movie := movies[i]
+		tasks[i] = Task{Key: movie.Key().String(), Run: func() error {
+			metadata, err := provider.FetchMetadata(movie, pins[movie.Key()], ctx, log)
+			if err != nil && err != ErrMovieNotFound {
+				return err
+			}
+			
+			mutex.Lock()
+			if err == nil {
+				movieKeyMetadata[movie.Key()] = &metadata
+			} else {
+				movieKeyMetadata[movie.Key()] = nil
+			}
+			mutex.Unlock()
+			return nil
+		}}
+	}
 	
-	return string(bytes), nil
+	return movieKeyMetadata, RunFetchTasks(tasks)
 }
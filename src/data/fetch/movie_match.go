@@ -0,0 +1,168 @@
+package fetch
+
+import (
+	"src/config"
+	"src/data/types"
+	"src/logging"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Candidate match of a movie as found by searching a metadata provider.
+type movieCandidate struct {
+	Id    string
+	Title string
+	Year  int
+	score float64
+}
+
+// Weights of the criteria of the match score.
+const (
+	titleWeight    = 0.6
+	yearWeight     = 0.25
+	directorWeight = 0.15
+)
+
+var seasonSuffixRegex = regexp.MustCompile("(?i)[\\s,:-]*\\bseason\\b.*$")
+
+// Titles to search for a movie in order: The full title and, for series, the title without the season (like
+// "Looking" for "Looking - Season 2").
+func searchTitles(title string) []string {
+	titles := []string{title}
+	if trimmed := strings.TrimSpace(seasonSuffixRegex.ReplaceAllString(title, "")); trimmed != "" && trimmed != title {
+		titles = append(titles, trimmed)
+	}
+	return titles
+}
+
+// Reduce a title to a form where variations in case, punctuation, and leading articles don't matter (such that
+// "Ant-Man" and "Ant Man" become identical).
+func normalizeMovieTitle(title string) string {
+	str := accents.Replace(strings.ToLower(title))
+	str = strings.Replace(str, "&", " and ", -1)
+	str = strings.Replace(str, "'", "", -1)
+	str = nonWordRegex.ReplaceAllString(str, " ")
+	
+	words := strings.Fields(str)
+	if len(words) > 1 && (words[0] == "the" || words[0] == "a" || words[0] == "an") {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// Similarity in [0, 1] of the title of a movie and the one of a candidate. The title is also compared without its
+// season as series are generally found by their name only.
+func titleSimilarity(title string, candidateTitle string) float64 {
+	c := normalizeMovieTitle(candidateTitle)
+	best := 0.0
+	for _, t := range searchTitles(title) {
+		n := normalizeMovieTitle(t)
+		if n == c {
+			return 1
+		}
+		if s := (levenshteinRatio(n, c) + wordSimilarity(strings.Fields(n), strings.Fields(c))) / 2; s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// Release years often differ by one between the data set and the provider (festival and theatrical releases).
+func yearSimilarity(year int, candidateYear int) float64 {
+	switch year - candidateYear {
+	case 0:
+		return 1
+	case -1, 1:
+		return 0.5
+	}
+	return 0
+}
+
+// Similarity of the director of a movie (which may name several directors) to the best matching one of a candidate.
+func directorSimilarity(director string, candidateDirectors []string) float64 {
+	d := strings.Fields(normalizeMovieTitle(director))
+	best := 0.0
+	for _, cd := range candidateDirectors {
+		if s := wordSimilarity(d, strings.Fields(normalizeMovieTitle(cd))); s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// Score in [0, 1] of how well a candidate matches a movie as the weighted average of the similarity of the title, the
+// release year, and the director. Years and directors only count if they're known for both.
+func matchScore(movie types.Movie, title string, year int, directors []string) float64 {
+	score := titleWeight * titleSimilarity(movie.Title, title)
+	weights := titleWeight
+	if movie.ReleaseYear != 0 && year != 0 {
+		score += yearWeight * yearSimilarity(movie.ReleaseYear, year)
+		weights += yearWeight
+	}
+	if movie.Director != "" && len(directors) > 0 {
+		score += directorWeight * directorSimilarity(movie.Director, directors)
+		weights += directorWeight
+	}
+	return score / weights
+}
+
+// Pick the best match of a movie among the candidates: The best candidates by title and year (as many as configured)
+// have their details fetched and are scored again including the director. Returns `ErrMovieNotFound` if no candidate
+// reaches the minimum score. The confidence of the match is set to its score.
+func bestMatch(movie types.Movie, candidates []movieCandidate, fetchDetails func(id string) (types.MovieMetadata, error), log logging.Logger) (types.MovieMetadata, error) {
+	minScore, maxCandidates := config.MovieMatchSettings()
+	
+	for i := range candidates {
+		c := &candidates[i]
+		c.score = matchScore(movie, c.Title, c.Year, nil)
+	}
+	sort.Stable(byCandidateScore(candidates))
+	if len(candidates) > maxCandidates {
+		candidates = candidates[:maxCandidates]
+	}
+	
+	var best types.MovieMetadata
+	bestScore := -1.0
+	for _, c := range candidates {
+		if matchScore(movie, c.Title, c.Year, []string{movie.Director}) < minScore {
+			// Not even a matching director would make up for the title and year.
+			continue
+		}
+		metadata, err := fetchDetails(c.Id)
+		if err == ErrMovieNotFound {
+			continue
+		}
+		if err != nil {
+			return types.MovieMetadata{}, err
+		}
+		
+		score := matchScore(movie, metadata.Title, metadata.Year, metadata.Directors)
+		log.Debugf("Candidate '%s' (%d, ID %s) of movie '%s' has score %.2f", metadata.Title, metadata.Year, c.Id, movie.Title, score)
+		if score > bestScore {
+			best = metadata
+			bestScore = score
+		}
+	}
+	
+	if bestScore < minScore {
+		if bestScore >= 0 {
+			log.Infof("Rejecting best match '%s' (%d) of movie '%s' with score %.2f", best.Title, best.Year, movie.Title, bestScore)
+		}
+		return types.MovieMetadata{}, ErrMovieNotFound
+	}
+	best.MatchConfidence = bestScore
+	return best, nil
+}
+
+type byCandidateScore []movieCandidate
+
+func (cs byCandidateScore) Len() int {
+	return len(cs)
+}
+func (cs byCandidateScore) Swap(i, j int) {
+	cs[i], cs[j] = cs[j], cs[i]
+}
+func (cs byCandidateScore) Less(i, j int) bool {
+	return cs[i].score > cs[j].score
+}
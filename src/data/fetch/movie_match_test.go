@@ -0,0 +1,151 @@
+package fetch
+
+import (
+	"src/data/types"
+	"src/logging"
+	"testing"
+)
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		title          string
+		candidateTitle string
+		want           float64
+	}{
+		{"Ant-Man", "Ant Man", 1},
+		{"Ant-Man", "ant-man", 1},
+		{"The Rock", "Rock", 1},
+		{"Looking - Season 2", "Looking", 1},
+		{"Looking: Season 1", "Looking", 1},
+		{"Mrs. Doubtfire", "Mrs Doubtfire", 1},
+	}
+	for _, test := range tests {
+		if got := titleSimilarity(test.title, test.candidateTitle); got != test.want {
+			t.Errorf("titleSimilarity(%q, %q) = %.2f, want %.2f", test.title, test.candidateTitle, got, test.want)
+		}
+	}
+}
+
+func TestTitleSimilarityOfDifferentTitles(t *testing.T) {
+	tests := []struct {
+		title          string
+		candidateTitle string
+	}{
+		{"Ant-Man", "Ant-Man and the Wasp"},
+		{"Vertigo", "Vertical Limit"},
+		{"The Rock", "Rocky"},
+	}
+	for _, test := range tests {
+		if got := titleSimilarity(test.title, test.candidateTitle); got >= 1 {
+			t.Errorf("titleSimilarity(%q, %q) = %.2f, want less than 1", test.title, test.candidateTitle, got)
+		}
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	bodySnatchers := types.Movie{Title: "Invasion of the Body Snatchers", ReleaseYear: 1978, Director: "Philip Kaufman"}
+	tests := []struct {
+		movie     types.Movie
+		title     string
+		year      int
+		directors []string
+		want      float64
+	}{
+		{types.Movie{Title: "Ant Man", ReleaseYear: 2015, Director: "Peyton Reed"}, "Ant-Man", 2015, []string{"Peyton Reed"}, 1},
+		{bodySnatchers, "Invasion of the Body Snatchers", 1978, []string{"Philip Kaufman"}, 1},
+		
+		// The remake and the original only share the title.
+		{bodySnatchers, "Invasion of the Body Snatchers", 1956, []string{"Don Siegel"}, titleWeight},
+		
+		// Years differing by one count half.
+		{bodySnatchers, "Invasion of the Body Snatchers", 1979, nil, (titleWeight + yearWeight / 2) / (titleWeight + yearWeight)},
+		
+		// Unknown years and directors don't count.
+		{types.Movie{Title: "Vertigo"}, "Vertigo", 1958, []string{"Alfred Hitchcock"}, 1},
+	}
+	for _, test := range tests {
+		got := matchScore(test.movie, test.title, test.year, test.directors)
+		if diff := got - test.want; diff < -1e-9 || diff > 1e-9 {
+			t.Errorf("matchScore(%+v, %q, %d, %v) = %.3f, want %.3f", test.movie, test.title, test.year, test.directors, got, test.want)
+		}
+	}
+}
+
+func TestBestMatch(t *testing.T) {
+	details := map[string]types.MovieMetadata{
+		"original": {ProviderId: "original", Title: "Invasion of the Body Snatchers", Year: 1956, Directors: []string{"Don Siegel"}},
+		"remake":   {ProviderId: "remake", Title: "Invasion of the Body Snatchers", Year: 1978, Directors: []string{"Philip Kaufman"}},
+		"ant-man":  {ProviderId: "ant-man", Title: "Ant-Man", Year: 2015, Directors: []string{"Peyton Reed"}},
+		"sequel":   {ProviderId: "sequel", Title: "Ant-Man and the Wasp", Year: 2018, Directors: []string{"Peyton Reed"}},
+	}
+	fetchDetails := func(id string) (types.MovieMetadata, error) {
+		if m, exists := details[id]; exists {
+			return m, nil
+		}
+		return types.MovieMetadata{}, ErrMovieNotFound
+	}
+	
+	tests := []struct {
+		movie      types.Movie
+		candidates []movieCandidate
+		want       string
+	}{
+		{
+			types.Movie{Title: "Invasion of the Body Snatchers", ReleaseYear: 1978, Director: "Philip Kaufman"},
+			[]movieCandidate{
+				{Id: "original", Title: "Invasion of the Body Snatchers", Year: 1956},
+				{Id: "remake", Title: "Invasion of the Body Snatchers", Year: 1978},
+			},
+			"remake",
+		},
+		{
+			types.Movie{Title: "Invasion of the Body Snatchers", ReleaseYear: 1956, Director: "Don Siegel"},
+			[]movieCandidate{
+				{Id: "remake", Title: "Invasion of the Body Snatchers", Year: 1978},
+				{Id: "original", Title: "Invasion of the Body Snatchers", Year: 1956},
+			},
+			"original",
+		},
+		{
+			types.Movie{Title: "Ant Man", ReleaseYear: 2015, Director: "Peyton Reed"},
+			[]movieCandidate{
+				{Id: "sequel", Title: "Ant-Man and the Wasp", Year: 2018},
+				{Id: "ant-man", Title: "Ant-Man", Year: 2015},
+			},
+			"ant-man",
+		},
+		{
+			// Candidates whose details can't be found are skipped.
+			types.Movie{Title: "Ant-Man", ReleaseYear: 2015},
+			[]movieCandidate{
+				{Id: "missing", Title: "Ant-Man", Year: 2015},
+				{Id: "ant-man", Title: "Ant-Man", Year: 2015},
+			},
+			"ant-man",
+		},
+	}
+	for _, test := range tests {
+		got, err := bestMatch(test.movie, test.candidates, fetchDetails, &logging.InitLogger{})
+		if err != nil {
+			t.Errorf("bestMatch(%+v) failed: %s", test.movie, err)
+			continue
+		}
+		if got.ProviderId != test.want {
+			t.Errorf("bestMatch(%+v) = %q, want %q", test.movie, got.ProviderId, test.want)
+		}
+		if got.MatchConfidence <= 0 || got.MatchConfidence > 1 {
+			t.Errorf("bestMatch(%+v) has confidence %.2f, want in (0, 1]", test.movie, got.MatchConfidence)
+		}
+	}
+}
+
+func TestBestMatchWithoutGoodCandidate(t *testing.T) {
+	movie := types.Movie{Title: "Vertigo", ReleaseYear: 1958, Director: "Alfred Hitchcock"}
+	candidates := []movieCandidate{{Id: "other", Title: "Vertical Limit", Year: 2000}}
+	fetchDetails := func(id string) (types.MovieMetadata, error) {
+		return types.MovieMetadata{ProviderId: id, Title: "Vertical Limit", Year: 2000, Directors: []string{"Martin Campbell"}}, nil
+	}
+	if got, err := bestMatch(movie, candidates, fetchDetails, &logging.InitLogger{}); err != ErrMovieNotFound {
+		t.Errorf("bestMatch(%+v) = %q, %v, want ErrMovieNotFound", movie, got.ProviderId, err)
+	}
+}
@@ -0,0 +1,68 @@
+package fetch
+
+import (
+	"src/config"
+	"sync"
+)
+
+// Task of a fan-out identified by a key (like the name of a location).
+type Task struct {
+	Key string
+	Run func() error
+}
+
+type TaskResult struct {
+	Key string
+	Err error
+}
+
+type TaskResults []TaskResult
+
+// Results of the tasks that failed.
+func (rs TaskResults) Failed() TaskResults {
+	var failed TaskResults
+	for _, r := range rs {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// Run tasks on a bounded number of workers and wait for all of them to complete. The results are in the order of the
+// tasks. Tasks sharing state must synchronize access to it themselves.
+func RunTasks(tasks []Task, concurrency int) TaskResults {
+	results := make(TaskResults, len(tasks))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > len(tasks) {
+		concurrency = len(tasks)
+	}
+	
+	indices := make(chan int, len(tasks))
+	for i := range tasks {
+		indices <- i
+	}
+	close(indices)
+	
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				// Each worker writes only to the results of its own tasks.
+				results[i] = TaskResult{Key: tasks[i].Key, Err: tasks[i].Run()}
+			}
+		}()
+	}
+	wg.Wait()
+	
+	return results
+}
+
+// Run tasks with the configured concurrency limit.
+func RunFetchTasks(tasks []Task) TaskResults {
+	return RunTasks(tasks, config.FetchConcurrency())
+}
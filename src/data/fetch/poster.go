@@ -0,0 +1,100 @@
+package fetch
+
+import (
+	"src/config"
+	"src/logging"
+	"appengine"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/gif"
+	_ "image/png"
+)
+
+// Quality of the JPEG encoding of thumbnails.
+const thumbnailQuality = 85
+
+// Download a poster image and resize it into thumbnails of the configured widths (keeping its aspect ratio, and never
+// scaling it up). Images with more than the configured number of pixels are refused. Returns the JPEG encoded
+// thumbnails by size name.
+func FetchPosterThumbnails(uri string, ctx appengine.Context, log logging.Logger) (map[string][]byte, error) {
+	log.Infof("Fetching poster from URL '%s'", uri)
+	data, err := Get(uri, PosterLimiter, ctx, log)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Check the dimensions before decoding as a small file may decode into a huge image.
+	widths, _, maxPixels := config.PosterSettings()
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if int64(cfg.Width) * int64(cfg.Height) > int64(maxPixels) {
+		return nil, fmt.Errorf("Poster at '%s' of size %dx%d exceeds %d pixels", uri, cfg.Width, cfg.Height, maxPixels)
+	}
+	
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Decoded %s poster of size %dx%d", format, img.Bounds().Dx(), img.Bounds().Dy())
+	
+	
+	thumbnails := make(map[string][]byte)
+	for size, width := range widths {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resizeImage(img, width), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+			return nil, err
+		}
+		thumbnails[size] = buf.Bytes()
+	}
+	return thumbnails, nil
+}
+
+// Scale an image down to the given width by averaging the source pixels covered by each pixel of the result (a box
+// filter, which is good enough for the scale factors of thumbnails).
+func resizeImage(src image.Image, width int) image.Image {
+	b := src.Bounds()
+	if width >= b.Dx() || width <= 0 {
+		width = b.Dx()
+	}
+	height := (b.Dy() * width + b.Dx() / 2) / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+	
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := boxRange(y, height, b.Min.Y, b.Dy())
+		for x := 0; x < width; x++ {
+			x0, x1 := boxRange(x, width, b.Min.X, b.Dx())
+			
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// Range of source coordinates covered by coordinate `i` of `n` in the result (at least one).
+func boxRange(i int, n int, min int, srcLen int) (int, int) {
+	from := min + i * srcLen / n
+	to := min + (i + 1) * srcLen / n
+	if to <= from {
+		to = from + 1
+	}
+	return from, to
+}
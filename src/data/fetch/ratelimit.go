@@ -0,0 +1,120 @@
+package fetch
+
+import (
+	"src/config"
+	"sync"
+	"time"
+)
+
+// Process-wide token bucket limiting the rate of requests to an external API. Tokens are added at a sustained rate up
+// to the burst size, and each request takes one (waiting for it if the bucket is empty). A quota response from the
+// provider pauses the bucket.
+type RateLimiter struct {
+	Api         string
+	mutex       sync.Mutex
+	perSecond   float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	waits       int
+	waited      time.Duration
+	pauses      int
+}
+
+var GeocoderLimiter = NewRateLimiter("geocoder")
+var NominatimLimiter = NewRateLimiter("nominatim")
+var OmdbLimiter = NewRateLimiter("omdb")
+var TmdbLimiter = NewRateLimiter("tmdb")
+var SocrataLimiter = NewRateLimiter("socrata")
+var PosterLimiter = NewRateLimiter("poster")
+
+var rateLimiters = []*RateLimiter{GeocoderLimiter, NominatimLimiter, OmdbLimiter, TmdbLimiter, SocrataLimiter, PosterLimiter}
+
+func NewRateLimiter(api string) *RateLimiter {
+	limit := config.ApiRateLimit(api)
+	return &RateLimiter{
+		Api:       api,
+		perSecond: limit.PerSecond,
+		burst:     float64(limit.Burst),
+		tokens:    float64(limit.Burst),
+		last:      time.Now(),
+	}
+}
+
+// Block until a request may be made.
+func (l *RateLimiter) Wait() {
+	if d := l.reserve(); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// Take a token (possibly going into debt) and return how long to wait before it may be used.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.perSecond
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.perSecond * float64(time.Second))
+	}
+	if paused := l.pausedUntil.Sub(now); paused > delay {
+		delay = paused
+	}
+	if delay > 0 {
+		l.waits++
+		l.waited += delay
+	}
+	return delay
+}
+
+// Stop handing out tokens for the given duration (e.g. because the provider reported that the quota is exceeded).
+func (l *RateLimiter) Pause(d time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	
+	until := time.Now().Add(d)
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	if l.tokens > 0 {
+		l.tokens = 0
+	}
+	l.pauses++
+}
+
+type RateLimiterStatus struct {
+	Api          string
+	PerSecond    float64
+	Burst        int
+	Waits        int
+	WaitedMillis int64
+	Pauses       int
+	PausedUntil  time.Time
+}
+
+func RateLimiterStatuses() []RateLimiterStatus {
+	statuses := make([]RateLimiterStatus, len(rateLimiters))
+	for i, l := range rateLimiters {
+		l.mutex.Lock()
+		statuses[i] = RateLimiterStatus{
+			Api:          l.Api,
+			PerSecond:    l.perSecond,
+			Burst:        int(l.burst),
+			Waits:        l.waits,
+			WaitedMillis: int64(l.waited / time.Millisecond),
+			Pauses:       l.pauses,
+			PausedUntil:  l.pausedUntil,
+		}
+		l.mutex.Unlock()
+	}
+	return statuses
+}
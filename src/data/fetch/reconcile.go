@@ -0,0 +1,176 @@
+package fetch
+
+import (
+	"src/config"
+	"src/data/types"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Separators of the names in credits of the data set (like "Umarji Anuradha, Jayendra, & Suba").
+var creditSeparatorRegex = regexp.MustCompile("\\s*(,|&|\\band\\b)\\s*")
+
+// Annotations of credits (like "(screenplay)").
+var creditAnnotationRegex = regexp.MustCompile("\\s*\\([^)]*\\)")
+
+// Names credited in a value of the data set.
+func SplitCredits(value string) []string {
+	var names []string
+	for _, n := range creditSeparatorRegex.Split(creditAnnotationRegex.ReplaceAllString(value, ""), -1) {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+func cleanCredits(names []string) []string {
+	var cleaned []string
+	for _, n := range names {
+		if n = strings.TrimSpace(creditAnnotationRegex.ReplaceAllString(n, "")); n != "" {
+			cleaned = append(cleaned, n)
+		}
+	}
+	return cleaned
+}
+
+func normalizePersonName(name string) string {
+	return strings.Join(strings.Fields(nonWordRegex.ReplaceAllString(accents.Replace(strings.ToLower(name)), " ")), " ")
+}
+
+// Whether two names are the same person (allowing for typos and differences like middle initials or names that are
+// left out).
+func sameName(n1 string, n2 string) bool {
+	w1 := strings.Fields(normalizePersonName(n1))
+	w2 := strings.Fields(normalizePersonName(n2))
+	if len(w1) > len(w2) {
+		w1, w2 = w2, w1
+	}
+	return len(w1) > 0 && (countMatchingWords(w1, w2) == len(w1) || wordSimilarity(w1, w2) >= 0.8)
+}
+
+// Names that don't match any of the others.
+func unmatchedNames(names []string, others []string) []string {
+	var unmatched []string
+	for _, n := range names {
+		matched := false
+		for _, o := range others {
+			if sameName(n, o) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, n)
+		}
+	}
+	return unmatched
+}
+
+// Compare the credits and release year of a movie in the data set field by field with the ones in its metadata (which
+// may be nil) and pick the values of each field by the configured source priority.
+func ReconcileCredits(movie types.Movie, metadata *types.MovieMetadata) types.ReconciledCredits {
+	m := metadata
+	if m == nil {
+		m = &types.MovieMetadata{}
+	}
+	provider := m.Provider
+	
+	c := types.ReconciledCredits{
+		Year:     types.ReconciledField{Field: "year"},
+		Director: types.ReconciledField{Field: "director", DataSf: SplitCredits(movie.Director), Metadata: cleanCredits(m.Directors)},
+		Writers:  types.ReconciledField{Field: "writers", DataSf: SplitCredits(movie.Writer), Metadata: cleanCredits(m.Writers)},
+		Actors:   types.ReconciledField{Field: "actors", DataSf: movie.Actors, Metadata: cleanCredits(m.Actors)},
+	}
+	if movie.ReleaseYear != 0 {
+		c.Year.DataSf = []string{strconv.Itoa(movie.ReleaseYear)}
+	}
+	if m.Year != 0 {
+		c.Year.Metadata = []string{strconv.Itoa(m.Year)}
+	}
+	
+	if movie.ReleaseYear != 0 && m.Year != 0 && movie.ReleaseYear != m.Year {
+		diff := movie.ReleaseYear - m.Year
+		if diff < 0 {
+			diff = -diff
+		}
+		c.Year.Discrepancy = fmt.Sprintf("Release year %d differs from %d of %s by %d year(s)", movie.ReleaseYear, m.Year, provider, diff)
+		// Festival and theatrical releases may be a year apart.
+		c.Year.Suspicious = diff > 1
+	}
+	
+	if len(c.Director.DataSf) > 0 && len(c.Director.Metadata) > 0 {
+		if missing := unmatchedNames(c.Director.DataSf, c.Director.Metadata); len(missing) > 0 {
+			c.Director.Discrepancy = fmt.Sprintf("Director(s) %s not credited by %s", quoteNames(missing), provider)
+			c.Director.Suspicious = true
+		}
+	}
+	
+	if len(c.Writers.DataSf) > 0 && len(c.Writers.Metadata) > 0 {
+		if missing := unmatchedNames(c.Writers.DataSf, c.Writers.Metadata); len(missing) > 0 {
+			c.Writers.Discrepancy = fmt.Sprintf("Writer(s) %s not credited by %s", quoteNames(missing), provider)
+			c.Writers.Suspicious = len(missing) == len(c.Writers.DataSf)
+		}
+	}
+	
+	if len(c.Actors.DataSf) > 0 && len(c.Actors.Metadata) > 0 {
+		// Providers only list the top billed actors, so actors missing from them are expected unless none match.
+		if missing := unmatchedNames(c.Actors.DataSf, c.Actors.Metadata); len(missing) > 0 {
+			c.Actors.Discrepancy = fmt.Sprintf("Actor(s) %s not among the ones listed by %s", quoteNames(missing), provider)
+			c.Actors.Suspicious = len(missing) == len(c.Actors.DataSf)
+		}
+	}
+	
+	priority := config.CreditSourcePriority()
+	for _, f := range []*types.ReconciledField{&c.Year, &c.Director, &c.Writers, &c.Actors} {
+		for _, source := range priority[f.Field] {
+			var values []string
+			switch source {
+			case types.SourceDataSf:
+				values = f.DataSf
+			case types.SourceMetadata:
+				values = f.Metadata
+			}
+			if len(values) > 0 {
+				f.Values = values
+				f.Source = source
+				break
+			}
+		}
+	}
+	return c
+}
+
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = "'" + n + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Fields of the movies (with metadata) whose sources disagree. Suspicious discrepancies come first.
+func CreditDiscrepancies(movies []types.IdMoviePair) []types.CreditDiscrepancy {
+	var suspicious []types.CreditDiscrepancy
+	var others []types.CreditDiscrepancy
+	for _, p := range movies {
+		if p.Metadata == nil {
+			continue
+		}
+		credits := ReconcileCredits(p.Movie, p.Metadata)
+		for _, f := range credits.Fields() {
+			if f.Discrepancy == "" {
+				continue
+			}
+			d := types.CreditDiscrepancy{MovieId: p.Id, Movie: p.Movie.Key(), Provider: p.Metadata.Provider, Field: f}
+			if f.Suspicious {
+				suspicious = append(suspicious, d)
+			} else {
+				others = append(others, d)
+			}
+		}
+	}
+	return append(suspicious, others...)
+}
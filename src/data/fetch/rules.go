@@ -0,0 +1,116 @@
+package fetch
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule for cleaning up, validating, or enriching an entry. A rule records any repair it makes or the reason it rejects
+// the entry on the report row of the entry. New rules are added by appending them to one of the lists below.
+type Rule struct {
+	Name  string
+	Apply func(e *Entry, row *RowReport)
+}
+
+var NormalizeRules = []Rule{
+	{"trim-whitespace", trimWhitespace},
+	{"clear-not-available", clearNotAvailable},
+	{"extract-release-year", extractReleaseYear},
+}
+
+var ValidateRules = []Rule{
+	{"require-title", requireTitle},
+	{"require-location", requireLocation},
+	{"check-release-year", checkReleaseYear},
+}
+
+var EnrichRules = []Rule{
+	{"parse-location-name", parseLocationName},
+}
+
+func ApplyRules(entries []Entry, rules []Rule, report *ValidationReport) {
+	for i := range entries {
+		e := &entries[i]
+		row := &report.Rows[e.Row]
+		for _, rule := range rules {
+			rule.Apply(e, row)
+		}
+	}
+}
+
+// Earliest and latest release year considered valid (the first movie filmed in San Francisco is from 1897 or so).
+const minReleaseYear = 1880
+const maxReleaseYearAhead = 10
+
+var yearRegex = regexp.MustCompile("\\b(18|19|20)\\d\\d\\b")
+
+func trimWhitespace(e *Entry, row *RowReport) {
+	for _, f := range e.fields() {
+		ts := strings.TrimSpace(*f.value)
+		if ts != *f.value {
+			row.Repair("Trimmed whitespace in '%s'", f.name)
+			*f.value = ts
+		}
+	}
+}
+
+func clearNotAvailable(e *Entry, row *RowReport) {
+	for _, f := range e.fields() {
+		if strings.TrimSpace(*f.value) == "N/A" {
+			row.Repair("Cleared 'N/A' in '%s'", f.name)
+			*f.value = ""
+		}
+	}
+}
+
+// Salvage release years from strings like "2011 (re-release)" or "c. 1958".
+func extractReleaseYear(e *Entry, row *RowReport) {
+	if e.Release_year == "" {
+		return
+	}
+	if _, err := strconv.Atoi(e.Release_year); err == nil {
+		return
+	}
+	if match := yearRegex.FindString(e.Release_year); match != "" {
+		row.Repair("Extracted release year %s from '%s'", match, e.Release_year)
+		e.Release_year = match
+	}
+}
+
+func requireTitle(e *Entry, row *RowReport) {
+	if cleaned(e.Title) == "" {
+		row.Reject("Missing title")
+	}
+}
+
+func requireLocation(e *Entry, row *RowReport) {
+	if cleaned(e.Locations) == "" {
+		row.Reject("Empty location")
+	}
+}
+
+// Movies without a release year are kept, but they can't be told apart from others with the same title.
+func checkReleaseYear(e *Entry, row *RowReport) {
+	str := cleaned(e.Release_year)
+	if str == "" {
+		row.Warn("Missing release year")
+		return
+	}
+	
+	year, err := strconv.Atoi(str)
+	if err != nil {
+		row.Reject("Bad release year '%s'", str)
+		return
+	}
+	
+	maxYear := time.Now().Year() + maxReleaseYearAhead
+	if year < minReleaseYear || maxYear < year {
+		row.Reject("Release year %d is out of range", year)
+	}
+}
+
+func parseLocationName(e *Entry, row *RowReport) {
+	e.Parts = ParseLocationName(cleaned(e.Locations))
+}
@@ -0,0 +1,134 @@
+package fetch
+
+import (
+	"fmt"
+	"time"
+)
+
+type RowStatus string
+
+const (
+	Accepted RowStatus = "accepted"
+	Repaired RowStatus = "repaired"
+	Rejected RowStatus = "rejected"
+)
+
+type RowReport struct {
+	Row      int
+	Title    string
+	Location string
+	Status   RowStatus
+	Reasons  []string
+	Warnings []string
+}
+
+// Record that the row was repaired (unless it's already rejected).
+func (r *RowReport) Repair(format string, args ...interface{}) {
+	if r.Status != Rejected {
+		r.Status = Repaired
+	}
+	r.Reasons = append(r.Reasons, fmt.Sprintf(format, args...))
+}
+
+func (r *RowReport) Reject(format string, args ...interface{}) {
+	r.Status = Rejected
+	r.Reasons = append(r.Reasons, fmt.Sprintf(format, args...))
+}
+
+// Record a problem with the row that neither repairs nor rejects it (like missing optional data).
+func (r *RowReport) Warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Rows that were accepted individually but disagree with other rows.
+type Conflict struct {
+	Title  string
+	Reason string
+	Rows   []int
+}
+
+type ValidationReport struct {
+	Source    string
+	Time      time.Time
+	Accepted  int
+	Repaired  int
+	Rejected  int
+	Warned    int
+	Rows      []RowReport
+	Conflicts []Conflict
+}
+
+// Create report with an accepted row for each of the entries.
+func NewValidationReport(source string, entries []Entry) *ValidationReport {
+	r := &ValidationReport{Source: source, Time: time.Now()}
+	r.Rows = make([]RowReport, len(entries))
+	for i, e := range entries {
+		r.Rows[i] = RowReport{Row: e.Row, Status: Accepted}
+	}
+	r.Update(entries)
+	return r
+}
+
+func (r *ValidationReport) addConflict(title string, rows []int, format string, args ...interface{}) {
+	r.Conflicts = append(r.Conflicts, Conflict{Title: title, Reason: fmt.Sprintf(format, args...), Rows: rows})
+}
+
+// Update the title and location of the rows from the (cleaned) entries and recount the statuses.
+func (r *ValidationReport) Update(entries []Entry) {
+	for _, e := range entries {
+		row := &r.Rows[e.Row]
+		row.Title = e.Title
+		row.Location = e.Locations
+	}
+	
+	r.Accepted = 0
+	r.Repaired = 0
+	r.Rejected = 0
+	r.Warned = 0
+	for _, row := range r.Rows {
+		if len(row.Warnings) > 0 {
+			r.Warned++
+		}
+		switch row.Status {
+		case Accepted:
+			r.Accepted++
+		case Repaired:
+			r.Repaired++
+		case Rejected:
+			r.Rejected++
+		}
+	}
+}
+
+// Rows with the given status.
+func (r *ValidationReport) RowsWithStatus(status RowStatus) []RowReport {
+	var rows []RowReport
+	for _, row := range r.Rows {
+		if row.Status == status {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Rows with warnings that haven't been rejected.
+func (r *ValidationReport) RowsWithWarnings() []RowReport {
+	var rows []RowReport
+	for _, row := range r.Rows {
+		if len(row.Warnings) > 0 && row.Status != Rejected {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Entries whose rows haven't been rejected.
+func DropRejected(entries []Entry, report *ValidationReport) []Entry {
+	valid := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if report.Rows[e.Row].Status != Rejected {
+			valid = append(valid, e)
+		}
+	}
+	return valid
+}
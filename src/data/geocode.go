@@ -0,0 +1,227 @@
+package data
+
+import (
+	"src/config"
+	"src/data/fetch"
+	"src/data/sqldb"
+	"src/data/types"
+	"src/logging"
+	"src/watch"
+	"appengine"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// Geocode the given canonical location names (mapped to the parts of the name) except for the ones whose lookup failed
+// recently. Found coordinates are cached, ambiguous ones queued for review, and failed lookups recorded. Returns the found coordinates and the number of
+// failed lookups.
+func GeocodeLocations(db *sql.DB, locNameParts map[string]types.LocationParts, geocoders []fetch.Geocoder, ctx appengine.Context, log logging.Logger) (map[string]types.Geocode, int, error) {
+	locNames := make([]string, 0, len(locNameParts))
+	for locName := range locNameParts {
+		locNames = append(locNames, locName)
+	}
+	
+	// Skip names whose lookup failed recently.
+	failures, err := sqldb.LoadGeocodeFailures(db, locNames, log)
+	if err != nil {
+		return nil, 0, err
+	}
+	now := time.Now()
+	missingCoords := make(map[string]*types.Geocode)
+	for _, locName := range locNames {
+		if f, exists := failures[locName]; exists && !f.Expired(now) {
+			log.Infof("Skipping location %s whose lookup failed until %s (%s)", locName, f.RetryAt, f.Reason)
+			continue
+		}
+		missingCoords[locName] = nil
+	}
+	
+	results := fetch.FetchMissingLocationNames(missingCoords, locNameParts, geocoders, ctx, log)
+	
+	// Results that were rejected in review aren't cached again but recorded as failures, while new results are reviewed
+	// again.
+	rejected, err := sqldb.LoadRejectedGeocodeReviews(db, locNames, log)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i, res := range results {
+		r, exists := rejected[res.Key]
+		if g := missingCoords[res.Key]; res.Err == nil && exists && r.HasCandidate(g.Coordinates) {
+			log.Infof("Discarding coordinates (%f, %f) of location %s that were rejected in review", g.Coordinates.Lat, g.Coordinates.Lng, res.Key)
+			missingCoords[res.Key] = nil
+			results[i].Err = fetch.ErrRejectedInReview
+		}
+	}
+	
+	// Store missing coordinates and record failed lookups.
+	if err := sqldb.StoreCoordinates(db, missingCoords, log); err != nil {
+		return nil, 0, err
+	}
+	var reviews []types.GeocodeReview
+	for locName, g := range missingCoords {
+		if g == nil {
+			continue
+		}
+		reason := fetch.ReviewReason(locName, locNameParts[locName], *g)
+		if _, exists := rejected[locName]; exists {
+			reason = fetch.ReviewAfterRejection
+		}
+		if reason != "" {
+			first := *g
+			first.Alternatives = nil
+			reviews = append(reviews, types.GeocodeReview{
+				LocationName: locName,
+				Reason:       reason,
+				Candidates:   append([]types.Geocode{first}, g.Alternatives...),
+				Status:       types.ReviewPending,
+				CreatedAt:    now,
+			})
+		}
+	}
+	if err := sqldb.StoreGeocodeReviews(db, reviews, log); err != nil {
+		return nil, 0, err
+	}
+	
+	var newFailures []types.GeocodeFailure
+	var resolvedNames []string
+	for _, res := range results {
+		if res.Err == nil {
+			if _, exists := failures[res.Key]; exists {
+				resolvedNames = append(resolvedNames, res.Key)
+			}
+			continue
+		}
+		var previous *types.GeocodeFailure
+		if f, exists := failures[res.Key]; exists {
+			previous = &f
+		}
+		newFailures = append(newFailures, fetch.NewGeocodeFailure(res.Key, res.Err, previous, now))
+	}
+	if err := sqldb.StoreGeocodeFailures(db, newFailures, log); err != nil {
+		return nil, 0, err
+	}
+	if _, err := sqldb.ClearGeocodeFailures(db, resolvedNames, log); err != nil {
+		return nil, 0, err
+	}
+	
+	geocodes := make(map[string]types.Geocode)
+	for locName, g := range missingCoords {
+		if g != nil {
+			geocodes[locName] = *g
+		}
+	}
+	return geocodes, len(newFailures), nil
+}
+
+// Start the background geocoding job if it has never been started (as for databases initialized before it existed).
+func startGeocodeJobUnlessExists(db *sql.DB, log logging.Logger) error {
+	job, err := sqldb.LoadGeocodeJob(db, log)
+	if err != nil || job != nil {
+		return err
+	}
+	log.Infof("Starting geocoding job of existing database")
+	return sqldb.ResetGeocodeJob(db, log)
+}
+
+// Prevents overlapping runs of the job on this instance.
+var geocodeJobMutex = &sync.Mutex{}
+
+// Continue the background geocoding job from its checkpoint until all location names have been processed or the time
+// budget of the run is used up. The checkpoint is saved after each batch such that an interrupted run loses at most one
+// batch of progress. Returns the progress of the job (nil if it has never been started).
+func RunGeocodeJob(db *sql.DB, geocoders []fetch.Geocoder, ctx appengine.Context, log logging.Logger) (*types.GeocodeJob, error) {
+	geocodeJobMutex.Lock()
+	defer geocodeJobMutex.Unlock()
+	
+	batchSize, budgetSeconds := config.GeocodeJobSettings()
+	sw := watch.NewStopWatch()
+	
+	job, err := sqldb.LoadGeocodeJob(db, log)
+	if err != nil || job == nil || job.Finished() {
+		return job, err
+	}
+	
+	for sw.TotalElapsedTimeMillis() < int64(budgetSeconds) * 1000 {
+		locNames, err := sqldb.LoadCanonicalLocationNames(db, job.Cursor, batchSize, log)
+		if err != nil {
+			return job, err
+		}
+		if len(locNames) == 0 {
+			job.UpdatedAt = time.Now()
+			job.FinishedAt = job.UpdatedAt
+			return job, sqldb.SaveGeocodeJob(db, job, log)
+		}
+		
+		locs := make([]types.Location, len(locNames))
+		for i, locName := range locNames {
+			locs[i].Name = locName
+		}
+		cached, err := sqldb.LoadCoordinates(db, locs, log)
+		if err != nil {
+			return job, err
+		}
+		
+		locNameParts := make(map[string]types.LocationParts)
+		for _, locName := range locNames {
+			if _, exists := cached[locName]; !exists {
+				locNameParts[locName] = fetch.ParseLocationName(locName)
+			}
+		}
+		found, failed, err := GeocodeLocations(db, locNameParts, geocoders, ctx, log)
+		if err != nil {
+			return job, err
+		}
+		
+		job.Processed += len(locNames)
+		job.Resolved += len(cached) + len(found)
+		job.Failed += failed
+		job.UpdatedAt = time.Now()
+		job.Cursor = locNames[len(locNames) - 1]
+		if len(locNames) < batchSize {
+			job.FinishedAt = job.UpdatedAt
+		}
+		if err := sqldb.SaveGeocodeJob(db, job, log); err != nil {
+			return job, err
+		}
+		
+		log.Infof("Geocoding job processed %d of %d location names (%d resolved, %d failed)", job.Processed, job.Total, job.Resolved, job.Failed)
+		if job.Finished() {
+			break
+		}
+	}
+	return job, nil
+}
+
+// Resolve the review of the geocode of a location name with the candidate at the given index or the given coordinates
+// (if `candidate` is negative). The choice replaces the cached coordinates.
+func AcceptGeocodeReview(db *sql.DB, review *types.GeocodeReview, candidate int, coords types.Coordinates, user string, log logging.Logger) error {
+	var geocode types.Geocode
+	if candidate >= 0 {
+		geocode = review.Candidates[candidate]
+	} else {
+		geocode = types.Geocode{Coordinates: coords, Source: "review", LocationType: types.LocationRooftop}
+	}
+	return sqldb.ResolveGeocodeReview(db, review.LocationName, &geocode, user, time.Now(), log)
+}
+
+// Resolve the review of the geocode of a location name by rejecting all candidates. The cached coordinates are deleted
+// and a failure is recorded such that the name isn't looked up again until the longest negative caching period has
+// passed (or the failure is cleared). The rejection is kept such that the lookup doesn't cache a rejected candidate
+// again.
+func RejectGeocodeReview(db *sql.DB, review *types.GeocodeReview, user string, log logging.Logger) error {
+	now := time.Now()
+	if err := sqldb.ResolveGeocodeReview(db, review.LocationName, nil, user, now, log); err != nil {
+		return err
+	}
+	
+	_, maxNotFoundHours, _ := config.NegativeCacheSettings()
+	failure := types.GeocodeFailure{
+		LocationName: review.LocationName,
+		Reason:       fetch.ErrRejectedInReview.Error(),
+		Attempts:     1,
+		FailedAt:     now,
+		RetryAt:      now.Add(time.Duration(maxNotFoundHours) * time.Hour),
+	}
+	return sqldb.StoreGeocodeFailures(db, []types.GeocodeFailure{failure}, log)
+}
@@ -2,7 +2,9 @@ package data
 
 import (
 	"src/data/fetch"
+	"src/data/pipeline"
 	"src/data/sqldb"
+	"src/data/types"
 	"src/logging"
 	"sync"
 	"database/sql"
@@ -10,30 +12,98 @@ import (
 
 var InitUpdateMutex = &sync.Mutex{}
 
-func Init(db *sql.DB, filename string, log logging.Logger) (bool, error) {
+// Whether this instance has migrated the tables of an already initialized database (guarded by `InitUpdateMutex`).
+var migrated = false
+
+// Initialize the database (unless it's already initialized) with the movies of the data set in `filename` and the
+// cached movie info and coordinates of the seed in `seedFileName` (if it exists). The seed is imported again by later
+// calls until it succeeds.
+func Init(db *sql.DB, filename string, seedFileName string, log logging.Logger) (bool, *fetch.ValidationReport, error) {
 	InitUpdateMutex.Lock()
 	defer InitUpdateMutex.Unlock()
 	
 	alreadyInitialized, err := IsInitialized(db)
 	if err != nil {
-		return !alreadyInitialized, err
+		return !alreadyInitialized, nil, err
 	}
 	
 	if alreadyInitialized {
 		log.Infof("Database is already initialized")
-		return false, nil
+		if err := migrateOnce(db, log); err != nil {
+			return false, nil, err
+		}
+		return false, nil, importSeedFileOnce(db, seedFileName, log)
 	}
 	
 	// Database is uninitialized. Try and initialize it...
 	
 	log.Infof("Initializing database from cached file...")
 	
-	movies, err := fetch.FetchFromFile(filename)
+	batch, err := pipeline.FromFile(filename, db).Run(log)
 	if err != nil {
-		return true, err
+		return true, batch.Report, err
+	}
+	
+	return true, batch.Report, importSeedFileOnce(db, seedFileName, log)
+}
+
+func migrateOnce(db *sql.DB, log logging.Logger) error {
+	if migrated {
+		return nil
+	}
+	
+	log.Infof("Migrating tables of existing database")
+	if err := sqldb.MigrateTables(db, log); err != nil {
+		return err
+	}
+	if err := migrateMovieInfo(db, log); err != nil {
+		return err
+	}
+	if err := migrateMovieInfoJson(db, log); err != nil {
+		return err
+	}
+	if err := startGeocodeJobUnlessExists(db, log); err != nil {
+		return err
+	}
+	migrated = true
+	return nil
+}
+
+// Normalize the raw OMDB responses cached by earlier versions into movie metadata. As the time when they were fetched
+// is unknown, they have expired.
+func migrateMovieInfo(db *sql.DB, log logging.Logger) error {
+	legacy, err := sqldb.LoadLegacyMovieInfoJsons(db, log)
+	if err != nil || len(legacy) == 0 {
+		return err
+	}
+	
+	log.Infof("Converting %d cached OMDB responses into movie metadata", len(legacy))
+	var entries []types.CachedMovieMetadata
+	for key, infoJson := range legacy {
+		metadata, err := fetch.ParseOmdbMovie(infoJson)
+		if err != nil {
+			// Deleted below such that it's fetched again on the next update.
+			log.Warningf("Dropping invalid info of movie '%s': %s", key.Title, err.Error())
+			continue
+		}
+		entries = append(entries, types.CachedMovieMetadata{Key: key, Metadata: metadata, Provider: "omdb"})
+	}
+	if err := sqldb.StoreMovieMetadata(db, entries, log); err != nil {
+		return err
+	}
+	return sqldb.DeleteLegacyMovieInfo(db, log)
+}
+
+// Move the movie metadata that earlier versions kept as JSON into the typed columns and tables (which also clears the
+// JSON).
+func migrateMovieInfoJson(db *sql.DB, log logging.Logger) error {
+	entries, err := sqldb.LoadMovieMetadataJsons(db, log)
+	if err != nil || len(entries) == 0 {
+		return err
 	}
 	
-	return true, sqldb.InitTablesAndStoreMovies(db, movies, log)
+	log.Infof("Converting %d movie infos kept as JSON into columns", len(entries))
+	return sqldb.StoreMovieMetadata(db, entries, log)
 }
 
 func IsInitialized(db *sql.DB) (bool, error) {
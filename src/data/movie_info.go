@@ -0,0 +1,178 @@
+package data
+
+import (
+	"src/config"
+	"src/data/fetch"
+	"src/data/sqldb"
+	"src/data/types"
+	"src/logging"
+	"src/watch"
+	"appengine"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Cache entry of metadata fetched from the provider at `now`. It expires after the configured TTL (which is longer for
+// movies that the provider didn't find).
+func NewCachedMovieMetadata(key types.MovieKey, provider string, metadata *types.MovieMetadata, now time.Time) types.CachedMovieMetadata {
+	ttlHours, notFoundTtlHours, _ := config.MovieInfoTtlSettings()
+	if metadata == nil {
+		ttlHours = notFoundTtlHours
+	}
+	return types.CachedMovieMetadata{
+		Key:       key,
+		Metadata:  metadata,
+		Provider:  provider,
+		FetchedAt: now,
+		ExpiresAt: now.Add(time.Duration(ttlHours) * time.Hour),
+	}
+}
+
+// Fetch and cache the metadata of the given movies whose metadata hasn't been cached yet. Movies whose metadata can't
+// be fetched are left out such that fetching it is attempted again on the next update.
+func FetchMissingMovieMetadata(db *sql.DB, provider fetch.MetadataProvider, movies []types.Movie, ctx appengine.Context, log logging.Logger) error {
+	movieKeys, err := sqldb.LoadMovieMetadataKeys(db, log)
+	if err != nil {
+		return err
+	}
+	
+	var missingInfoMovies []types.Movie
+	for _, movie := range movies {
+		if !movieKeys[movie.Key()] {
+			missingInfoMovies = append(missingInfoMovies, movie)
+		}
+	}
+	
+	pins, err := loadMovieMetadataPins(db, log)
+	if err != nil {
+		return err
+	}
+	movieKeyInfo, results := fetch.FetchMoviesMetadata(provider, missingInfoMovies, pins, ctx, log)
+	for _, r := range results.Failed() {
+		log.Errorf("Info could not be fetched for movie '%s': %s", r.Key, r.Err.Error())
+	}
+	
+	now := time.Now()
+	var entries []types.CachedMovieMetadata
+	for key, metadata := range movieKeyInfo {
+		entries = append(entries, NewCachedMovieMetadata(key, provider.Name(), metadata, now))
+	}
+	return sqldb.StoreMovieMetadata(db, entries, log)
+}
+
+// Prevents overlapping runs of the refresher on this instance.
+var movieInfoRefreshMutex = &sync.Mutex{}
+
+// Fetch the metadata of movies whose cached metadata has expired (in batches until none is left or the time budget of
+// the run is used up). If fetching fails, or the provider no longer finds a movie that it found before, the previous
+// value is kept and the refresh is attempted again later. Returns the number of refreshed and failed entries.
+func RefreshMovieMetadata(db *sql.DB, provider fetch.MetadataProvider, ctx appengine.Context, log logging.Logger) (int, int, error) {
+	movieInfoRefreshMutex.Lock()
+	defer movieInfoRefreshMutex.Unlock()
+	
+	batchSize, budgetSeconds := config.MovieInfoRefreshSettings()
+	_, _, retryMinutes := config.MovieInfoTtlSettings()
+	sw := watch.NewStopWatch()
+	
+	pins, err := loadMovieMetadataPins(db, log)
+	if err != nil {
+		return 0, 0, err
+	}
+	
+	refreshed := 0
+	failed := 0
+	for sw.TotalElapsedTimeMillis() < int64(budgetSeconds) * 1000 {
+		now := time.Now()
+		expired, err := sqldb.LoadExpiredMovieMetadata(db, now, batchSize, log)
+		if err != nil || len(expired) == 0 {
+			return refreshed, failed, err
+		}
+		
+		movies := make([]types.Movie, len(expired))
+		for i, c := range expired {
+			movies[i] = types.Movie{Title: c.Key.Title, ReleaseYear: c.Key.ReleaseYear, Director: c.Key.Director}
+		}
+		movieKeyInfo, results := fetch.FetchMoviesMetadata(provider, movies, pins, ctx, log)
+		for _, r := range results.Failed() {
+			log.Warningf("Info could not be refreshed for movie '%s': %s", r.Key, r.Err.Error())
+		}
+		
+		now = time.Now()
+		entries := make([]types.CachedMovieMetadata, len(expired))
+		for i, c := range expired {
+			metadata, fetched := movieKeyInfo[c.Key]
+			if fetched && (metadata != nil || c.Metadata == nil) {
+				entries[i] = NewCachedMovieMetadata(c.Key, provider.Name(), metadata, now)
+				refreshed++
+				continue
+			}
+			if fetched {
+				log.Warningf("Keeping info of movie '%s' that is no longer found by '%s'", c.Key.Title, provider.Name())
+			}
+			c.ExpiresAt = now.Add(time.Duration(retryMinutes) * time.Minute)
+			entries[i] = c
+			failed++
+		}
+		if err := sqldb.StoreMovieMetadata(db, entries, log); err != nil {
+			return refreshed, failed, err
+		}
+		
+		log.Infof("Refreshed info of %d movies (%d failed)", refreshed, failed)
+		if len(expired) < batchSize {
+			break
+		}
+	}
+	return refreshed, failed, nil
+}
+
+// Pinned IMDb IDs by movie.
+func loadMovieMetadataPins(db *sql.DB, log logging.Logger) (map[types.MovieKey]string, error) {
+	pins, err := sqldb.LoadMovieMetadataPins(db, log)
+	if err != nil {
+		return nil, err
+	}
+	keyImdbIds := make(map[types.MovieKey]string)
+	for _, p := range pins {
+		keyImdbIds[p.Key] = p.ImdbId
+	}
+	return keyImdbIds, nil
+}
+
+var imdbIdRegex = regexp.MustCompile("^tt\\d+$")
+
+// Whether the given string is an IMDb ID (like "tt0052357").
+func IsImdbId(id string) bool {
+	return imdbIdRegex.MatchString(id)
+}
+
+// Pin the IMDb ID of a movie (or unpin it if `imdbId` is empty) and fetch the movie's metadata accordingly. If fetching
+// fails, the cached metadata is expired such that the background refresher attempts it again.
+func PinMovieMetadata(db *sql.DB, provider fetch.MetadataProvider, movie types.Movie, imdbId string, user string, ctx appengine.Context, log logging.Logger) error {
+	if imdbId != "" && !IsImdbId(imdbId) {
+		return fmt.Errorf("Invalid IMDb ID '%s'", imdbId)
+	}
+	
+	var err error
+	if imdbId == "" {
+		err = sqldb.DeleteMovieMetadataPin(db, movie.Key(), log)
+	} else {
+		err = sqldb.StoreMovieMetadataPin(db, types.MovieMetadataPin{Key: movie.Key(), ImdbId: imdbId, UpdatedBy: user, UpdatedAt: time.Now()}, log)
+	}
+	if err != nil {
+		return err
+	}
+	
+	pins := map[types.MovieKey]string{movie.Key(): imdbId}
+	movieKeyInfo, results := fetch.FetchMoviesMetadata(provider, []types.Movie{movie}, pins, ctx, log)
+	for _, r := range results.Failed() {
+		if err := sqldb.ExpireMovieMetadata(db, movie.Key(), log); err != nil {
+			return err
+		}
+		return r.Err
+	}
+	entry := NewCachedMovieMetadata(movie.Key(), provider.Name(), movieKeyInfo[movie.Key()], time.Now())
+	return sqldb.StoreMovieMetadata(db, []types.CachedMovieMetadata{entry}, log)
+}
@@ -0,0 +1,60 @@
+package data
+
+import (
+	"src/data/sqldb"
+	"src/data/types"
+	"src/logging"
+	"database/sql"
+)
+
+// Load the movies matching the filter and set the cached coordinates on their locations (without fetching missing
+// ones). As coordinates are cached under the canonical names of merged location name variants, those are returned as
+// well (by the names that have been merged).
+func LoadMoviesWithCoordinates(db *sql.DB, filter types.MovieFilter, log logging.Logger) ([]types.IdMoviePair, map[string]string, error) {
+	movies, err := sqldb.LoadMovies(db, filter, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	var locNames []string
+	for _, p := range movies {
+		for _, loc := range p.Movie.Locations {
+			locNames = append(locNames, loc.Name)
+		}
+	}
+	aliases, err := sqldb.LoadLocationAliases(db, locNames, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	seen := make(map[string]bool)
+	var canonicalLocs []types.Location
+	for _, locName := range locNames {
+		if canonical, exists := aliases[locName]; exists {
+			locName = canonical
+		}
+		if !seen[locName] {
+			seen[locName] = true
+			canonicalLocs = append(canonicalLocs, types.Location{Name: locName})
+		}
+	}
+	locNameCoords, err := sqldb.LoadCoordinates(db, canonicalLocs, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	for i := range movies {
+		locs := movies[i].Movie.Locations
+		for j := range locs {
+			locName := locs[j].Name
+			if canonical, exists := aliases[locName]; exists {
+				locName = canonical
+			}
+			if g, exists := locNameCoords[locName]; exists {
+				locs[j].Geocode = g
+				locs[j].Coordinates = g.Coordinates
+			}
+		}
+	}
+	return movies, aliases, nil
+}
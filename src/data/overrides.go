@@ -0,0 +1,98 @@
+package data
+
+import (
+	"src/data/fetch"
+	"src/data/sqldb"
+	"src/data/types"
+	"src/logging"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Set coordinate overrides under the canonical names of the given location names (as coordinates are cached under
+// those).
+func SetCoordinateOverrides(db *sql.DB, overrides []types.CoordinateOverride, log logging.Logger) error {
+	locNames := make([]string, len(overrides))
+	for i, o := range overrides {
+		locNames[i] = o.LocationName
+	}
+	aliases, err := sqldb.LoadLocationAliases(db, locNames, log)
+	if err != nil {
+		return err
+	}
+	
+	for i := range overrides {
+		if canonical, exists := aliases[overrides[i].LocationName]; exists {
+			log.Infof("Setting override of location '%s' on its canonical name '%s'", overrides[i].LocationName, canonical)
+			overrides[i].LocationName = canonical
+		}
+	}
+	return sqldb.StoreCoordinateOverrides(db, overrides, log)
+}
+
+// Check that coordinates set by an admin are within the bounding box of San Francisco (catching e.g. swapped latitude and
+// longitude).
+func CheckOverrideCoordinates(c types.Coordinates) error {
+	if !fetch.SfBounds().Contains(c) {
+		return fmt.Errorf("Coordinates (%f, %f) are outside of San Francisco", c.Lat, c.Lng)
+	}
+	return nil
+}
+
+// Parse coordinate overrides from CSV records of the form "location name, latitude, longitude[, note]". A first record
+// whose coordinates aren't numbers is taken to be a header and skipped. Errors refer to records by their number (which
+// may differ from the line number as quoted fields may span lines).
+func ParseCoordinateOverridesCsv(r io.Reader, user string, now time.Time) ([]types.CoordinateOverride, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	
+	var overrides []types.CoordinateOverride
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 || len(record) > 4 {
+			return nil, fmt.Errorf("Record %d: expected 3 or 4 fields but got %d", n, len(record))
+		}
+		
+		name := strings.TrimSpace(record[0])
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(record[1]), 32)
+		lng, lngErr := strconv.ParseFloat(strings.TrimSpace(record[2]), 32)
+		if latErr != nil || lngErr != nil {
+			if n == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("Record %d: invalid coordinates '%s', '%s'", n, record[1], record[2])
+		}
+		if name == "" {
+			return nil, fmt.Errorf("Record %d: missing location name", n)
+		}
+		coords := types.Coordinates{Lat: float32(lat), Lng: float32(lng)}
+		if err := CheckOverrideCoordinates(coords); err != nil {
+			return nil, fmt.Errorf("Record %d: %s", n, err)
+		}
+		
+		var note string
+		if len(record) == 4 {
+			note = strings.TrimSpace(record[3])
+		}
+		overrides = append(overrides, types.CoordinateOverride{
+			LocationName: name,
+			Coordinates:  coords,
+			Note:         note,
+			UpdatedBy:    user,
+			UpdatedAt:    now,
+		})
+	}
+	return overrides, nil
+}
@@ -0,0 +1,32 @@
+package pipeline
+
+import (
+	"src/data/fetch"
+	"src/data/sqldb"
+	"src/data/types"
+	"src/logging"
+	"database/sql"
+)
+
+// Propose merges of near-duplicate location names of the movies and store them. Strong matches are merged right away
+// while weak ones are left for an admin to review.
+func MergeLocationNames(db *sql.DB, movies []types.Movie, log logging.Logger) error {
+	var locNames []string
+	for _, movie := range movies {
+		for _, loc := range movie.Locations {
+			locNames = append(locNames, loc.Name)
+		}
+	}
+	
+	merges := fetch.ProposeLocationMerges(locNames)
+	
+	pendingCount := 0
+	for _, m := range merges {
+		if m.Status == types.MergePending {
+			pendingCount++
+		}
+	}
+	log.Infof("Proposed %d location name merges (%d pending review)", len(merges), pendingCount)
+	
+	return sqldb.StoreLocationMerges(db, merges, log)
+}
@@ -0,0 +1,79 @@
+package pipeline
+
+import (
+	"src/config"
+	"src/data/fetch"
+	"src/data/types"
+	"src/logging"
+	"src/watch"
+)
+
+// Data passed through the stages of the ingestion pipeline.
+type Batch struct {
+	Source  string
+	Entries []fetch.Entry
+	Movies  []types.Movie
+	Report  *fetch.ValidationReport
+}
+
+// Stage of the ingestion pipeline. Stages are run in order on the same batch.
+type Stage interface {
+	Name() string
+	Run(batch *Batch, log logging.Logger) error
+}
+
+type Pipeline struct {
+	Stages []Stage
+}
+
+func New(stages ...Stage) *Pipeline {
+	return &Pipeline{Stages: stages}
+}
+
+// Stages that cannot be disabled: Without reading, an empty batch would replace the movies in the database, and
+// without storing, the pipeline would have no effect.
+var requiredStages = map[string]bool{"read": true, "store": true}
+
+// Run the stages that aren't disabled by configuration. The (partially processed) batch is returned even if a stage
+// fails.
+func (p *Pipeline) Run(log logging.Logger) (*Batch, error) {
+	disabled := config.DisabledPipelineStages()
+	batch := &Batch{Report: fetch.NewValidationReport("", nil)}
+	
+	sw := watch.NewStopWatch()
+	for _, stage := range p.Stages {
+		name := stage.Name()
+		if disabled[name] {
+			if requiredStages[name] {
+				log.Warningf("Running pipeline stage '%s' although it's disabled as it's required", name)
+			} else {
+				log.Infof("Skipping disabled pipeline stage '%s'", name)
+				continue
+			}
+		}
+		
+		log.Infof("Running pipeline stage '%s'", name)
+		if err := stage.Run(batch, log); err != nil {
+			log.Errorf("Pipeline stage '%s' failed after %d ms: %s", name, sw.ElapsedTimeMillis(true), err.Error())
+			return batch, err
+		}
+		log.Infof(
+			"Pipeline stage '%s' completed in %d ms with %d entries and %d movies",
+			name,
+			sw.ElapsedTimeMillis(true),
+			len(batch.Entries),
+			len(batch.Movies),
+		)
+	}
+	
+	if batch.Report != nil {
+		log.Infof(
+			"Pipeline completed in %d ms: %d accepted, %d repaired, and %d rejected rows",
+			sw.TotalElapsedTimeMillis(),
+			batch.Report.Accepted,
+			batch.Report.Repaired,
+			batch.Report.Rejected,
+		)
+	}
+	return batch, nil
+}
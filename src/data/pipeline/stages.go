@@ -0,0 +1,136 @@
+package pipeline
+
+import (
+	"src/config"
+	"src/data/fetch"
+	"src/data/sqldb"
+	"src/logging"
+	"appengine"
+	"database/sql"
+	"errors"
+	"io/ioutil"
+)
+
+// Pipeline that reads the data set from the given URL and stores it in the database.
+func FromUrl(url string, db *sql.DB, ctx appengine.Context) *Pipeline {
+	return New(&ReadStage{Url: url, Ctx: ctx}, NormalizeStage(), ValidateStage(), EnrichStage(), &StoreStage{Db: db})
+}
+
+// Pipeline that reads the data set from the given (cached) file and stores it in the database.
+func FromFile(fileName string, db *sql.DB) *Pipeline {
+	return New(&ReadStage{FileName: fileName}, NormalizeStage(), ValidateStage(), EnrichStage(), &StoreStage{Db: db})
+}
+
+// Stage that reads entries from either a URL or a file.
+type ReadStage struct {
+	Url      string
+	Ctx      appengine.Context
+	FileName string
+}
+
+func (s *ReadStage) Name() string {
+	return "read"
+}
+
+func (s *ReadStage) Run(batch *Batch, log logging.Logger) error {
+	var bytes []byte
+	var err error
+	if s.Url != "" {
+		log.Infof("Fetching from URL '%s'", s.Url)
+		batch.Source = s.Url
+		bytes, err = fetch.FetchBytes(s.Url, s.Ctx, log)
+	} else {
+		log.Infof("Fetching from file '%s'", s.FileName)
+		batch.Source = s.FileName
+		bytes, err = ioutil.ReadFile(s.FileName)
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof("Fetched %d bytes", len(bytes))
+	
+	entries, err := fetch.ReadEntries(bytes)
+	if err != nil {
+		return err
+	}
+	
+	batch.Entries = entries
+	batch.Report = fetch.NewValidationReport(batch.Source, entries)
+	return nil
+}
+
+// Stage that applies rules to all entries and optionally drops the rejected ones.
+type RulesStage struct {
+	StageName    string
+	Rules        []fetch.Rule
+	DropRejected bool
+}
+
+// Stage that cleans up the fields of the entries.
+func NormalizeStage() *RulesStage {
+	return &RulesStage{StageName: "normalize", Rules: fetch.NormalizeRules}
+}
+
+// Stage that rejects invalid entries and removes them from the batch.
+func ValidateStage() *RulesStage {
+	return &RulesStage{StageName: "validate", Rules: fetch.ValidateRules, DropRejected: true}
+}
+
+// Stage that adds derived data (like the parts of location names) to the entries.
+func EnrichStage() *RulesStage {
+	return &RulesStage{StageName: "enrich", Rules: fetch.EnrichRules}
+}
+
+func (s *RulesStage) Name() string {
+	return s.StageName
+}
+
+func (s *RulesStage) Run(batch *Batch, log logging.Logger) error {
+	disabled := config.DisabledPipelineRules()
+	
+	var rules []fetch.Rule
+	for _, rule := range s.Rules {
+		if disabled[rule.Name] {
+			log.Infof("Skipping disabled rule '%s'", rule.Name)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	
+	fetch.ApplyRules(batch.Entries, rules, batch.Report)
+	batch.Report.Update(batch.Entries)
+	
+	if s.DropRejected {
+		batch.Entries = fetch.DropRejected(batch.Entries, batch.Report)
+	}
+	return nil
+}
+
+// Stage that groups the entries into movies, replaces the movies in the database, merges near-duplicate location
+// names, and restarts the background geocoding job. An empty batch (like when every row was rejected) is refused
+// rather than clearing the movies in the database.
+type StoreStage struct {
+	Db *sql.DB
+}
+
+func (s *StoreStage) Name() string {
+	return "store"
+}
+
+func (s *StoreStage) Run(batch *Batch, log logging.Logger) error {
+	batch.Movies = fetch.EntriesToMovies(batch.Entries, batch.Report)
+	log.Infof("Resolved %d movies", len(batch.Movies))
+	if len(batch.Movies) == 0 {
+		return errors.New("Refusing to store empty batch (the movies in the database are kept)")
+	}
+	
+	if err := sqldb.InitTablesAndStoreMovies(s.Db, batch.Movies, log); err != nil {
+		return err
+	}
+	if err := MergeLocationNames(s.Db, batch.Movies, log); err != nil {
+		return err
+	}
+	
+	// Make the background job geocode the new set of location names.
+	return sqldb.ResetGeocodeJob(s.Db, log)
+}
@@ -0,0 +1,75 @@
+package data
+
+import (
+	"src/data/fetch"
+	"src/data/sqldb"
+	"src/data/types"
+	"src/logging"
+	"appengine"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+)
+
+// Version of the poster of a movie downloaded from the given URL. Poster URLs include it such that browsers may cache
+// them for long: the IDs of movies change with each update, but the version only matches the same poster of the same
+// movie.
+func PosterVersion(key types.MovieKey, sourceUrl string) string {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s\x00%d\x00%s\x00%s", key.Title, key.ReleaseYear, key.Director, sourceUrl)
+	return strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+// Poster thumbnail of a movie in the given size. The poster is downloaded from the poster URL of the movie's metadata
+// (and cached in all sizes) unless it's already cached from that URL. If downloading fails, a poster cached from an
+// earlier URL is kept. Returns nil if the movie has no poster.
+func LoadPoster(db *sql.DB, movie types.Movie, size string, ctx appengine.Context, log logging.Logger) (*types.Poster, error) {
+	cached, err := sqldb.LoadPoster(db, movie.Key(), size, log)
+	if err != nil {
+		return nil, err
+	}
+	info, err := sqldb.LoadMovieMetadata(db, movie.Key(), log)
+	if err != nil {
+		return nil, err
+	}
+	var posterUrl string
+	if info != nil && info.Metadata != nil {
+		posterUrl = info.Metadata.PosterUrl
+	}
+	if posterUrl == "" || (cached != nil && cached.SourceUrl == posterUrl) {
+		return cached, nil
+	}
+	
+	thumbnails, err := fetch.FetchPosterThumbnails(posterUrl, ctx, log)
+	if err != nil {
+		if cached != nil {
+			log.Warningf("Keeping cached poster of movie '%s' as it could not be fetched: %s", movie.Title, err.Error())
+			return cached, nil
+		}
+		return nil, err
+	}
+	
+	now := time.Now()
+	var posters []types.Poster
+	for s, data := range thumbnails {
+		posters = append(posters, types.Poster{
+			Key:         movie.Key(),
+			Size:        s,
+			ContentType: "image/jpeg",
+			Data:        data,
+			SourceUrl:   posterUrl,
+			FetchedAt:   now,
+		})
+	}
+	if err := sqldb.StorePosters(db, posters, log); err != nil {
+		return nil, err
+	}
+	for i := range posters {
+		if posters[i].Size == size {
+			return &posters[i], nil
+		}
+	}
+	return nil, nil
+}
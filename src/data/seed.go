@@ -0,0 +1,158 @@
+package data
+
+import (
+	"src/data/sqldb"
+	"src/data/types"
+	"src/logging"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// Snapshot of the cached movie info and coordinates and of the pinned IMDb IDs (without coordinate overrides, which are
+// managed separately).
+func ExportSeed(db *sql.DB, log logging.Logger) (*types.Seed, error) {
+	movieInfo, err := sqldb.LoadAllMovieMetadata(db, log)
+	if err != nil {
+		return nil, err
+	}
+	coordinates, err := sqldb.LoadAllCoordinates(db, log)
+	if err != nil {
+		return nil, err
+	}
+	pins, err := sqldb.LoadMovieMetadataPins(db, log)
+	if err != nil {
+		return nil, err
+	}
+	
+	log.Infof("Exporting seed with %d movie infos, %d coordinates, and %d pins", len(movieInfo), len(coordinates), len(pins))
+	return &types.Seed{MovieInfo: movieInfo, Coordinates: coordinates, Pins: pins}, nil
+}
+
+// Import the movie info, coordinates, and pins of a seed into the caches. Entries that are already cached (or pinned)
+// are kept. Returns the numbers of imported movie infos and coordinates.
+func ImportSeed(db *sql.DB, seed *types.Seed, log logging.Logger) (int, int, error) {
+	movieKeys, err := sqldb.LoadMovieMetadataKeys(db, log)
+	if err != nil {
+		return 0, 0, err
+	}
+	var movieInfo []types.CachedMovieMetadata
+	for _, c := range seed.MovieInfo {
+		if !movieKeys[c.Key] && c.Provider != "" {
+			movieInfo = append(movieInfo, c)
+		}
+	}
+	if err := sqldb.StoreMovieMetadata(db, movieInfo, log); err != nil {
+		return 0, 0, err
+	}
+	
+	// Existing coordinates are kept (the insert would replace them).
+	var locs []types.Location
+	for locName := range seed.Coordinates {
+		locs = append(locs, types.Location{Name: locName})
+	}
+	existing, err := sqldb.LoadCoordinates(db, locs, log)
+	if err != nil {
+		return 0, 0, err
+	}
+	coordinates := make(map[string]*types.Geocode)
+	for locName := range seed.Coordinates {
+		if _, exists := existing[locName]; exists {
+			continue
+		}
+		g := seed.Coordinates[locName]
+		coordinates[locName] = &g
+	}
+	if err := sqldb.StoreCoordinates(db, coordinates, log); err != nil {
+		return 0, 0, err
+	}
+	
+	// Pins set in this database are kept.
+	existingPins, err := sqldb.LoadMovieMetadataPins(db, log)
+	if err != nil {
+		return 0, 0, err
+	}
+	pinned := make(map[types.MovieKey]bool)
+	for _, p := range existingPins {
+		pinned[p.Key] = true
+	}
+	pins := 0
+	for _, p := range seed.Pins {
+		if pinned[p.Key] {
+			continue
+		}
+		if err := sqldb.StoreMovieMetadataPin(db, p, log); err != nil {
+			return 0, 0, err
+		}
+		pins++
+	}
+	
+	log.Infof("Imported seed with %d new movie infos, %d new coordinates, and %d new pins", len(movieInfo), len(coordinates), pins)
+	return len(movieInfo), len(coordinates), nil
+}
+
+func ReadSeed(r io.Reader) (*types.Seed, error) {
+	var seed types.Seed
+	if err := json.NewDecoder(r).Decode(&seed); err != nil {
+		return nil, err
+	}
+	return &seed, nil
+}
+
+// Name of the stamp of the import of the seed file.
+const seedImportedStamp = "seed_imported"
+
+// Whether this instance has found the seed file to be imported or missing (guarded by `InitUpdateMutex`).
+var seeded = false
+
+// Import the seed file unless that has already succeeded. A failed import is thus attempted again by the next
+// initialization. If the file doesn't exist, the import isn't recorded such that a seed deployed later is imported by
+// the instances running it.
+func importSeedFileOnce(db *sql.DB, fileName string, log logging.Logger) error {
+	if seeded {
+		return nil
+	}
+	
+	importedAt, err := sqldb.LoadStamp(db, seedImportedStamp)
+	if err != nil {
+		return err
+	}
+	if importedAt.IsZero() {
+		log.Infof("Loading movie info and coordinates from seed file...")
+		imported, err := ImportSeedFile(db, fileName, log)
+		if err != nil {
+			return err
+		}
+		if imported {
+			if err := sqldb.SetStamp(db, seedImportedStamp, time.Now(), log); err != nil {
+				return err
+			}
+		}
+	}
+	seeded = true
+	return nil
+}
+
+// Import the seed in the given file. A missing file is skipped. Returns whether the seed was imported.
+func ImportSeedFile(db *sql.DB, fileName string, log logging.Logger) (bool, error) {
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		log.Infof("Seed file '%s' does not exist", fileName)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	
+	seed, err := ReadSeed(file)
+	if err != nil {
+		return false, err
+	}
+	if _, _, err := ImportSeed(db, seed, log); err != nil {
+		return false, err
+	}
+	return true, nil
+}
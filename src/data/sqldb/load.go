@@ -1,11 +1,15 @@
 package sqldb
 
 import (
+	"src/config"
 	"src/data/types"
 	"src/logging"
 	"src/watch"
 	"sort"
+	"strings"
+	"time"
 	"database/sql"
+	"encoding/json"
 )
 
 func LoadMovie(db *sql.DB, id int64, log logging.Logger) (types.Movie, error) {
@@ -41,14 +45,14 @@ func LoadMovie(db *sql.DB, id int64, log logging.Logger) (types.Movie, error) {
 func LoadLocations(tx *sql.Tx, id int64, locs *[]types.Location, log logging.Logger) error {
 	log.Debugf("Querying locations for movie %d", id)
 	
-	rows, err := tx.Query("SELECT name, fun_fact FROM locations AS l WHERE l.movie_id = ?", id)
+	rows, err := tx.Query("SELECT " + locationColumns + " FROM locations AS l WHERE l.movie_id = ?", id)
 	if err != nil {
 		return err
 	}
 	
 	return forEachRow(rows, func (rows *sql.Rows) error {
 		var loc types.Location
-		err := rows.Scan(&loc.Name, &loc.FunFact)
+		err := scanLocation(rows, &loc)
 		if err != nil {
 			return err
 		}
@@ -58,6 +62,26 @@ func LoadLocations(tx *sql.Tx, id int64, locs *[]types.Location, log logging.Log
 	})
 }
 
+const locationColumns = "name, fun_fact, landmark, address, street_1, street_2, neighborhood, range_street, range_from, range_to"
+
+// Scan row of `locationColumns` into location. Any extra destinations are scanned from the preceding columns.
+func scanLocation(rows *sql.Rows, loc *types.Location, dest ...interface{}) error {
+	p := &loc.Parts
+	return rows.Scan(append(
+		dest,
+		&loc.Name,
+		&loc.FunFact,
+		&p.Landmark,
+		&p.Address,
+		&p.Intersection[0],
+		&p.Intersection[1],
+		&p.Neighborhood,
+		&p.Range.Street,
+		&p.Range.From,
+		&p.Range.To,
+	)...)
+}
+
 func LoadActors(tx *sql.Tx, movieId int64, actors *[]string, log logging.Logger) error {
 	log.Debugf("Querying actors for movie %d", movieId)
 	
@@ -81,14 +105,43 @@ func LoadActors(tx *sql.Tx, movieId int64, actors *[]string, log logging.Logger)
 	})
 }
 
-func LoadMovies(db *sql.DB, log logging.Logger) ([]types.IdMoviePair, error) {
+// Conditions (on movies `m` and their metadata `i`) of a filter along with their arguments.
+func movieFilterConditions(filter types.MovieFilter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	
+	listTables := map[string]string{genresTable: filter.Genre, languagesTable: filter.Language, countriesTable: filter.Country}
+	for _, table := range movieInfoListTables {
+		if value := listTables[table]; value != "" {
+			conditions = append(conditions, "EXISTS (SELECT 1 FROM " + table + " l WHERE l.movie_title = m.title AND l.release_year = m.release_year AND l.director = m.director AND l.name = ?)")
+			args = append(args, value)
+		}
+	}
+	if filter.MinImdbRating > 0 {
+		conditions = append(conditions, "i.imdb_rating >= ?")
+		args = append(args, filter.MinImdbRating)
+	}
+	if filter.MaxRuntimeMinutes > 0 {
+		conditions = append(conditions, "i.runtime_minutes > 0 AND i.runtime_minutes <= ?")
+		args = append(args, filter.MaxRuntimeMinutes)
+	}
+	return conditions, args
+}
+
+// Load the movies matching the filter along with their locations, actors, and metadata (if it has been fetched).
+func LoadMovies(db *sql.DB, filter types.MovieFilter, log logging.Logger) ([]types.IdMoviePair, error) {
 	var movies []types.IdMoviePair
 	
 	err := transaction(db, func (tx *sql.Tx) error {
-		log.Debugf("Querying movies")
+		log.Debugf("Querying movies matching %+v", filter)
 		
-		// Loading all movies.
-		rows, err := tx.Query("SELECT id, title, writer, director, distributor, production_company, release_year FROM movies")
+		stmt := `SELECT m.id, m.title, m.writer, m.director, m.distributor, m.production_company, m.release_year FROM movies m
+			LEFT JOIN movie_info i ON i.movie_title = m.title AND i.release_year = m.release_year AND i.director = m.director`
+		conditions, args := movieFilterConditions(filter)
+		if len(conditions) > 0 {
+			stmt += " WHERE " + strings.Join(conditions, " AND ")
+		}
+		rows, err := tx.Query(stmt, args...)
 		if err != nil {
 			return err
 		}
@@ -128,9 +181,26 @@ func LoadMovies(db *sql.DB, log logging.Logger) ([]types.IdMoviePair, error) {
 			return err
 		}
 		
+		// Load metadata.
+		keys := make([]types.MovieKey, 0, len(idMovieMap))
+		for _, m := range idMovieMap {
+			keys = append(keys, m.Key())
+		}
+		keyMetadata := make(map[types.MovieKey]*types.MovieMetadata)
+		if len(keys) > 0 {
+			condition, keyArgs := movieKeysCondition(keys)
+			entries, err := queryMovieMetadata(tx, condition + " AND provider != ''", keyArgs...)
+			if err != nil {
+				return err
+			}
+			for _, c := range entries {
+				keyMetadata[c.Key] = c.Metadata
+			}
+		}
+		
 		movies = make([]types.IdMoviePair, 0, len(idMovieMap))
 		for mId, m := range idMovieMap {
-			movies = append(movies, types.IdMoviePair{Id: mId, Movie: *m})
+			movies = append(movies, types.IdMoviePair{Id: mId, Movie: *m, Metadata: keyMetadata[m.Key()]})
 		}
 		
 		return nil
@@ -146,7 +216,7 @@ func LoadMovies(db *sql.DB, log logging.Logger) ([]types.IdMoviePair, error) {
 func LoadAllLocations(tx *sql.Tx, idMovieMap map[int64]*types.Movie, log logging.Logger) error {
 	log.Debugf("Querying all locations")
 	
-	rows, err := tx.Query("SELECT movie_id, name, fun_fact FROM locations")
+	rows, err := tx.Query("SELECT movie_id, " + locationColumns + " FROM locations")
 	if err != nil {
 		return err
 	}
@@ -154,14 +224,15 @@ func LoadAllLocations(tx *sql.Tx, idMovieMap map[int64]*types.Movie, log logging
 	return forEachRow(rows, func (rows *sql.Rows) error {
 		var id int64
 		var loc types.Location
-		err := rows.Scan(&id, &loc.Name, &loc.FunFact)
+		err := scanLocation(rows, &loc, &id)
 		if err != nil {
 			return err
 		}
 		
 		movie, exists := idMovieMap[id]
 		if !exists {
-			panic("Unexpected location ID...")
+			// Movie has been filtered out.
+			return nil
 		}
 		movie.Locations = append(movie.Locations, loc)
 		return nil
@@ -207,7 +278,8 @@ func LoadAllActors(tx *sql.Tx, idMovieMap map[int64]*types.Movie, log logging.Lo
 		
 		movie, exists := idMovieMap[movieId]
 		if !exists {
-			panic("Unexpected movie ID...")
+			// Movie has been filtered out.
+			return nil
 		}
 		
 		actorName, exists := idActorMap[actorId]
@@ -225,40 +297,274 @@ func LoadAllActors(tx *sql.Tx, idMovieMap map[int64]*types.Movie, log logging.Lo
 	return nil
 }
 
-func LoadMovieInfoJson(db *sql.DB, title string, log logging.Logger) (string, error) {
+const movieMetadataColumns = `movie_title, release_year, director, provider, fetched_at, expires_at, found, provider_id,
+	imdb_id, title, year, released, runtime_minutes, rated, plot, awards, poster_url, metascore, imdb_rating, imdb_votes,
+	match_confidence`
+
+func scanMovieMetadata(rows *sql.Rows) (types.CachedMovieMetadata, error) {
+	var c types.CachedMovieMetadata
+	var m types.MovieMetadata
+	var fetchedAt int64
+	var expiresAt int64
+	var found bool
+	err := rows.Scan(
+		&c.Key.Title,
+		&c.Key.ReleaseYear,
+		&c.Key.Director,
+		&c.Provider,
+		&fetchedAt,
+		&expiresAt,
+		&found,
+		&m.ProviderId,
+		&m.ImdbId,
+		&m.Title,
+		&m.Year,
+		&m.Released,
+		&m.RuntimeMinutes,
+		&m.Rated,
+		&m.Plot,
+		&m.Awards,
+		&m.PosterUrl,
+		&m.Metascore,
+		&m.ImdbRating,
+		&m.ImdbVotes,
+		&m.MatchConfidence,
+	)
+	if fetchedAt != 0 {
+		c.FetchedAt = time.Unix(fetchedAt, 0)
+	}
+	c.ExpiresAt = time.Unix(expiresAt, 0)
+	if found {
+		m.Provider = c.Provider
+		c.Metadata = &m
+	}
+	return c, err
+}
+
+// Query cached movie metadata (including its lists) with the given condition and order.
+func queryMovieMetadata(tx *sql.Tx, conditionAndOrder string, args ...interface{}) ([]types.CachedMovieMetadata, error) {
+	var entries []types.CachedMovieMetadata
+	rows, err := tx.Query("SELECT " + movieMetadataColumns + " FROM movie_info WHERE " + conditionAndOrder, args...)
+	if err != nil {
+		return nil, err
+	}
+	err = forEachRow(rows, func (rows *sql.Rows) error {
+		c, err := scanMovieMetadata(rows)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, c)
+		return nil
+	})
+	if err != nil || len(entries) == 0 {
+		return entries, err
+	}
+	
+	keyMetadata := make(map[types.MovieKey]*types.MovieMetadata)
+	keys := make([]types.MovieKey, 0, len(entries))
+	for _, c := range entries {
+		if c.Metadata != nil {
+			keyMetadata[c.Key] = c.Metadata
+			keys = append(keys, c.Key)
+		}
+	}
+	if len(keys) == 0 {
+		return entries, nil
+	}
+	condition, keyArgs := movieKeysCondition(keys)
+	
+	for _, table := range movieInfoListTables {
+		rows, err := tx.Query("SELECT movie_title, release_year, director, name FROM " + table + " WHERE " + condition + " ORDER BY position", keyArgs...)
+		if err != nil {
+			return nil, err
+		}
+		err = forEachRow(rows, func (rows *sql.Rows) error {
+			var k types.MovieKey
+			var name string
+			if err := rows.Scan(&k.Title, &k.ReleaseYear, &k.Director, &name); err != nil {
+				return err
+			}
+			m := keyMetadata[k]
+			switch table {
+			case genresTable:
+				m.Genres = append(m.Genres, name)
+			case languagesTable:
+				m.Languages = append(m.Languages, name)
+			case countriesTable:
+				m.Countries = append(m.Countries, name)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	
+	rows, err = tx.Query("SELECT movie_title, release_year, director, role, name FROM movie_info_credits WHERE " + condition + " ORDER BY position", keyArgs...)
+	if err != nil {
+		return nil, err
+	}
+	err = forEachRow(rows, func (rows *sql.Rows) error {
+		var k types.MovieKey
+		var role string
+		var name string
+		if err := rows.Scan(&k.Title, &k.ReleaseYear, &k.Director, &role, &name); err != nil {
+			return err
+		}
+		m := keyMetadata[k]
+		switch role {
+		case types.CreditDirector:
+			m.Directors = append(m.Directors, name)
+		case types.CreditWriter:
+			m.Writers = append(m.Writers, name)
+		case types.CreditActor:
+			m.Actors = append(m.Actors, name)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// Load the cached metadata of a movie. Returns nil if it hasn't been fetched.
+func LoadMovieMetadata(db *sql.DB, key types.MovieKey, log logging.Logger) (*types.CachedMovieMetadata, error) {
 	sw := watch.NewStopWatch()
 	
-	var info string
+	var cached *types.CachedMovieMetadata
 	err := transaction(db, func (tx *sql.Tx) error {
-		row := tx.QueryRow("SELECT info_json FROM movie_info WHERE movie_title = ?", title)
-		return row.Scan(&info)
+		entries, err := queryMovieMetadata(
+			tx,
+			"movie_title = ? AND release_year = ? AND director = ? AND provider != ''",
+			key.Title,
+			key.ReleaseYear,
+			key.Director,
+		)
+		if len(entries) > 0 {
+			cached = &entries[0]
+		}
+		return err
 	})
 	
-	if err != nil {
-		log.Infof("Loaded info for movie '%s' in %d ms", title, sw.TotalElapsedTimeMillis())
+	if err == nil {
+		log.Infof("Loaded info for movie '%s' (%d) in %d ms", key.Title, key.ReleaseYear, sw.TotalElapsedTimeMillis())
+	}
+	
+	return cached, err
+}
+
+// Load the cached metadata that has expired by `now` (the longest expired first).
+func LoadExpiredMovieMetadata(db *sql.DB, now time.Time, limit int, log logging.Logger) ([]types.CachedMovieMetadata, error) {
+	log.Debugf("Querying up to %d expired movie infos", limit)
+	
+	var expired []types.CachedMovieMetadata
+	err := transaction(db, func (tx *sql.Tx) error {
+		var err error
+		expired, err = queryMovieMetadata(tx, "provider != '' AND expires_at <= ? ORDER BY expires_at LIMIT ?", now.Unix(), limit)
+		return err
+	})
+	return expired, err
+}
+
+// Load all cached movie metadata.
+func LoadAllMovieMetadata(db *sql.DB, log logging.Logger) ([]types.CachedMovieMetadata, error) {
+	log.Debugf("Querying all movie infos")
+	
+	var entries []types.CachedMovieMetadata
+	err := transaction(db, func (tx *sql.Tx) error {
+		var err error
+		entries, err = queryMovieMetadata(tx, "provider != '' ORDER BY movie_title, release_year, director")
+		return err
+	})
+	return entries, err
+}
+
+// Load the metadata that earlier versions kept as JSON in `info_json` (along with its cache times).
+func LoadMovieMetadataJsons(db *sql.DB, log logging.Logger) ([]types.CachedMovieMetadata, error) {
+	var entries []types.CachedMovieMetadata
+	err := transaction(db, func (tx *sql.Tx) error {
+		rows, err := tx.Query(
+			"SELECT movie_title, release_year, director, provider, fetched_at, expires_at, info_json FROM movie_info WHERE provider != '' AND info_json != ''",
+		)
+		if err != nil {
+			return err
+		}
+		
+		return forEachRow(rows, func (rows *sql.Rows) error {
+			var c types.CachedMovieMetadata
+			var infoJson string
+			var fetchedAt int64
+			var expiresAt int64
+			err := rows.Scan(&c.Key.Title, &c.Key.ReleaseYear, &c.Key.Director, &c.Provider, &fetchedAt, &expiresAt, &infoJson)
+			if err != nil {
+				return err
+			}
+			if fetchedAt != 0 {
+				c.FetchedAt = time.Unix(fetchedAt, 0)
+			}
+			c.ExpiresAt = time.Unix(expiresAt, 0)
+			c.Metadata = &types.MovieMetadata{}
+			if err := json.Unmarshal([]byte(infoJson), c.Metadata); err != nil {
+				log.Warningf("Dropping invalid info of movie '%s': %s", c.Key.Title, err.Error())
+				c.Metadata = nil
+				c.ExpiresAt = time.Time{}
+			}
+			entries = append(entries, c)
+			return nil
+		})
+	})
+	
+	if err == nil {
+		log.Infof("Loaded %d movie infos kept as JSON", len(entries))
 	}
 	
-	return info, err
+	return entries, err
 }
 
-func LoadMovieInfoJsons(db *sql.DB, log logging.Logger) (map[string]string, error) {
+// Load the keys of the movies whose metadata has been fetched (whether or not the provider found the movie).
+func LoadMovieMetadataKeys(db *sql.DB, log logging.Logger) (map[types.MovieKey]bool, error) {
 	// TODO Parallelize (if the API allows it) and consider using memcached (with expiration) instead of SQL.
 	
 	sw := watch.NewStopWatch()
-	movieInfo := make(map[string]string)
+	keys := make(map[types.MovieKey]bool)
+	err := transaction(db, func (tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT movie_title, release_year, director FROM movie_info")
+		if err != nil {
+			return err
+		}
+		
+		return forEachRow(rows, func (rows *sql.Rows) error {
+			var key types.MovieKey
+			if err := rows.Scan(&key.Title, &key.ReleaseYear, &key.Director); err != nil {
+				return err
+			}
+			keys[key] = true
+			return nil
+		})
+	})
+	
+	if err == nil {
+		log.Infof("Fetched info keys of %d movies in %d ms", len(keys), sw.TotalElapsedTimeMillis())
+	}
+	
+	return keys, err
+}
+
+// Load the raw OMDB responses cached by earlier versions (which didn't record the provider).
+func LoadLegacyMovieInfoJsons(db *sql.DB, log logging.Logger) (map[types.MovieKey]string, error) {
+	sw := watch.NewStopWatch()
+	movieInfo := make(map[types.MovieKey]string)
 	err := transaction(db, func (tx *sql.Tx) error {
-		rows, err := tx.Query("SELECT movie_title, info_json FROM movie_info")
+		rows, err := tx.Query("SELECT movie_title, release_year, director, info_json FROM movie_info WHERE provider = ''")
 		if err != nil {
 			return err
 		}
 		
 		return forEachRow(rows, func (rows *sql.Rows) error {
-			var movieTitle string
+			var key types.MovieKey
 			var infoJson string
-			if err := rows.Scan(&movieTitle, &infoJson); err != nil {
+			if err := rows.Scan(&key.Title, &key.ReleaseYear, &key.Director, &infoJson); err != nil {
 				return err
 			}
-			movieInfo[movieTitle] = infoJson
+			movieInfo[key] = infoJson
 			return nil
 		})
 	})
@@ -270,22 +576,58 @@ func LoadMovieInfoJsons(db *sql.DB, log logging.Logger) (map[string]string, erro
 	return movieInfo, err
 }
 
-func LoadCoordinates(db *sql.DB, locs []types.Location, log logging.Logger) (map[string]types.Coordinates, error) {
+const geocodeColumns = `location_name, lat, lng, source, formatted_address, location_type, place_types,
+	viewport_south, viewport_west, viewport_north, viewport_east, matched_query`
+
+func scanGeocode(rows *sql.Rows) (string, types.Geocode, error) {
+	var locName string
+	var g types.Geocode
+	var placeTypes string
+	err := rows.Scan(
+		&locName,
+		&g.Coordinates.Lat,
+		&g.Coordinates.Lng,
+		&g.Source,
+		&g.FormattedAddress,
+		&g.LocationType,
+		&placeTypes,
+		&g.Viewport.South,
+		&g.Viewport.West,
+		&g.Viewport.North,
+		&g.Viewport.East,
+		&g.Query,
+	)
+	if placeTypes != "" {
+		g.PlaceTypes = strings.Split(placeTypes, ",")
+	}
+	return locName, g, err
+}
+
+// Load the cached coordinates of the given locations. Coordinate overrides take precedence over the cache. Cached
+// coordinates outside of the bounding box of San Francisco (like ones cached before it was checked or changed) are
+// left out such that they're geocoded again.
+func LoadCoordinates(db *sql.DB, locs []types.Location, log logging.Logger) (map[string]types.Geocode, error) {
 	sw := watch.NewStopWatch()
 	
+	south, west, north, east := config.SfBoundingBox()
+	bounds := types.Bounds{South: south, West: west, North: north, East: east}
+	
 	locNames := make([]interface{}, 0, len(locs))
 	for _, loc := range locs {
 		locName := loc.Name
 		locNames = append(locNames, locName)
 	}
 	
-	locCoords := make(map[string]types.Coordinates)
+	locCoords := make(map[string]types.Geocode)
+	if len(locs) == 0 {
+		return locCoords, nil
+	}
 	
 	err := transaction(db, func (tx *sql.Tx) error {
 		// Construct string with format "(?, ?, ..., ?)".
 		prpStmtStr := fancyRepeat("(", "?", len(locs), ", ", ")")
 		
-		stmt := "SELECT location_name, lat, lng FROM coordinates WHERE location_name IN " + prpStmtStr
+		stmt := "SELECT " + geocodeColumns + " FROM coordinates WHERE location_name IN " + prpStmtStr
 		log.Infof("Executing query '%s'", stmt)
 		
 		rows, err := tx.Query(stmt, locNames...)
@@ -293,16 +635,36 @@ func LoadCoordinates(db *sql.DB, locs []types.Location, log logging.Logger) (map
 			return err
 		}
 		
+		err = forEachRow(rows, func (rows *sql.Rows) error {
+			locName, g, err := scanGeocode(rows)
+			if err != nil {
+				return err
+			}
+			if !bounds.Contains(g.Coordinates) {
+				log.Warningf("Ignoring cached coordinates (%f, %f) of location %s outside of San Francisco", g.Coordinates.Lat, g.Coordinates.Lng, locName)
+				return nil
+			}
+			
+			locCoords[locName] = g
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		
+		rows, err = tx.Query("SELECT location_name, lat, lng FROM coordinate_overrides WHERE location_name IN " + prpStmtStr, locNames...)
+		if err != nil {
+			return err
+		}
+		
 		return forEachRow(rows, func (rows *sql.Rows) error {
 			var locName string
-			var lat float32
-			var lng float32
-			err := rows.Scan(&locName, &lat, &lng)
-			if err != nil {
+			g := types.Geocode{Source: types.OverrideSource}
+			if err := rows.Scan(&locName, &g.Coordinates.Lat, &g.Coordinates.Lng); err != nil {
 				return err
 			}
 			
-			locCoords[locName] = types.Coordinates{Lat: lat, Lng: lng}
+			locCoords[locName] = g
 			return nil
 		})
 	})
@@ -313,3 +675,418 @@ func LoadCoordinates(db *sql.DB, locs []types.Location, log logging.Logger) (map
 	
 	return locCoords, err
 }
+
+// Load all cached coordinates (without the overrides).
+func LoadAllCoordinates(db *sql.DB, log logging.Logger) (map[string]types.Geocode, error) {
+	log.Debugf("Querying all coordinates")
+	
+	locCoords := make(map[string]types.Geocode)
+	err := transaction(db, func (tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT " + geocodeColumns + " FROM coordinates")
+		if err != nil {
+			return err
+		}
+		
+		return forEachRow(rows, func (rows *sql.Rows) error {
+			locName, g, err := scanGeocode(rows)
+			if err != nil {
+				return err
+			}
+			locCoords[locName] = g
+			return nil
+		})
+	})
+	return locCoords, err
+}
+
+// Load the canonical names of the given location names that have been merged into one. Names that haven't been merged
+// are not included.
+func LoadLocationAliases(db *sql.DB, locNames []string, log logging.Logger) (map[string]string, error) {
+	aliases := make(map[string]string)
+	if len(locNames) == 0 {
+		return aliases, nil
+	}
+	
+	sw := watch.NewStopWatch()
+	
+	args := make([]interface{}, 0, len(locNames) + 1)
+	args = append(args, types.MergeMerged)
+	for _, locName := range locNames {
+		args = append(args, locName)
+	}
+	
+	err := transaction(db, func (tx *sql.Tx) error {
+		// Construct string with format "(?, ?, ..., ?)".
+		prpStmtStr := fancyRepeat("(", "?", len(locNames), ", ", ")")
+		
+		rows, err := tx.Query("SELECT alias, canonical FROM location_aliases WHERE status = ? AND alias IN " + prpStmtStr, args...)
+		if err != nil {
+			return err
+		}
+		
+		return forEachRow(rows, func (rows *sql.Rows) error {
+			var alias string
+			var canonical string
+			if err := rows.Scan(&alias, &canonical); err != nil {
+				return err
+			}
+			aliases[alias] = canonical
+			return nil
+		})
+	})
+	
+	if err == nil {
+		log.Infof("Loaded %d location aliases in %d ms", len(aliases), sw.TotalElapsedTimeMillis())
+	}
+	
+	return aliases, err
+}
+
+func LoadLocationMerges(db *sql.DB, status string, log logging.Logger) ([]types.LocationMerge, error) {
+	log.Debugf("Querying location name merges with status '%s'", status)
+	
+	var merges []types.LocationMerge
+	err := transaction(db, func (tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT alias, canonical, score, status FROM location_aliases WHERE status = ? ORDER BY score DESC", status)
+		if err != nil {
+			return err
+		}
+		
+		return forEachRow(rows, func (rows *sql.Rows) error {
+			var m types.LocationMerge
+			if err := rows.Scan(&m.Alias, &m.Canonical, &m.Score, &m.Status); err != nil {
+				return err
+			}
+			merges = append(merges, m)
+			return nil
+		})
+	})
+	return merges, err
+}
+
+const geocodeFailureColumns = "location_name, reason, attempts, failed_at, retry_at"
+
+func scanGeocodeFailure(rows *sql.Rows) (types.GeocodeFailure, error) {
+	var f types.GeocodeFailure
+	var failedAt int64
+	var retryAt int64
+	if err := rows.Scan(&f.LocationName, &f.Reason, &f.Attempts, &failedAt, &retryAt); err != nil {
+		return f, err
+	}
+	f.FailedAt = time.Unix(failedAt, 0)
+	f.RetryAt = time.Unix(retryAt, 0)
+	return f, nil
+}
+
+// Load the negative entries of the coordinates cache for the given location names (whether or not they have expired).
+func LoadGeocodeFailures(db *sql.DB, locNames []string, log logging.Logger) (map[string]types.GeocodeFailure, error) {
+	failures := make(map[string]types.GeocodeFailure)
+	if len(locNames) == 0 {
+		return failures, nil
+	}
+	
+	args := make([]interface{}, len(locNames))
+	for i, n := range locNames {
+		args[i] = n
+	}
+	
+	stmt := "SELECT " + geocodeFailureColumns + " FROM geocode_failures WHERE location_name IN " + fancyRepeat("(", "?", len(args), ", ", ")")
+	rows, err := db.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	
+	err = forEachRow(rows, func (rows *sql.Rows) error {
+		f, err := scanGeocodeFailure(rows)
+		if err != nil {
+			return err
+		}
+		failures[f.LocationName] = f
+		return nil
+	})
+	return failures, err
+}
+
+func LoadAllGeocodeFailures(db *sql.DB, log logging.Logger) ([]types.GeocodeFailure, error) {
+	log.Infof("Loading all geocode failures")
+	
+	rows, err := db.Query("SELECT " + geocodeFailureColumns + " FROM geocode_failures ORDER BY retry_at, location_name")
+	if err != nil {
+		return nil, err
+	}
+	
+	var failures []types.GeocodeFailure
+	err = forEachRow(rows, func (rows *sql.Rows) error {
+		f, err := scanGeocodeFailure(rows)
+		if err != nil {
+			return err
+		}
+		failures = append(failures, f)
+		return nil
+	})
+	return failures, err
+}
+
+// Distinct location names where merged aliases are replaced by their canonical names (under which coordinates are
+// cached). Takes the merged status as argument.
+const canonicalLocationNamesQuery = `SELECT DISTINCT COALESCE(a.canonical, l.name) AS name
+	FROM locations l LEFT JOIN location_aliases a ON a.alias = l.name AND a.status = ?`
+
+// Load up to `limit` canonical location names that come after `after` in order.
+func LoadCanonicalLocationNames(db *sql.DB, after string, limit int, log logging.Logger) ([]string, error) {
+	rows, err := db.Query(
+		"SELECT name FROM (" + canonicalLocationNamesQuery + ") n WHERE name > ? ORDER BY name LIMIT ?",
+		types.MergeMerged,
+		after,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	
+	var locNames []string
+	err = forEachRow(rows, func (rows *sql.Rows) error {
+		var locName string
+		if err := rows.Scan(&locName); err != nil {
+			return err
+		}
+		locNames = append(locNames, locName)
+		return nil
+	})
+	return locNames, err
+}
+
+// Load the checkpoint of the background geocoding job or nil if the job has never been started.
+func LoadGeocodeJob(db *sql.DB, log logging.Logger) (*types.GeocodeJob, error) {
+	row := db.QueryRow("SELECT cursor_name, total, processed, resolved, failed, started_at, updated_at, finished_at FROM geocode_job WHERE id = 1")
+	
+	var job types.GeocodeJob
+	var startedAt int64
+	var updatedAt int64
+	var finishedAt int64
+	err := row.Scan(&job.Cursor, &job.Total, &job.Processed, &job.Resolved, &job.Failed, &startedAt, &updatedAt, &finishedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	job.StartedAt = time.Unix(startedAt, 0)
+	job.UpdatedAt = time.Unix(updatedAt, 0)
+	if finishedAt != 0 {
+		job.FinishedAt = time.Unix(finishedAt, 0)
+	}
+	return &job, nil
+}
+
+// Load the time of the stamp with the given name (zero if it was never set).
+func LoadStamp(db *sql.DB, name string) (time.Time, error) {
+	var stampedAt int64
+	err := db.QueryRow("SELECT stamped_at FROM stamps WHERE name = ?", name).Scan(&stampedAt)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, stampedAt), nil
+}
+
+// Load the cached poster thumbnail of a movie in the given size (nil if it isn't cached).
+func LoadPoster(db *sql.DB, key types.MovieKey, size string, log logging.Logger) (*types.Poster, error) {
+	log.Debugf("Querying %s poster of movie '%s'", size, key.Title)
+	
+	var poster *types.Poster
+	err := transaction(db, func (tx *sql.Tx) error {
+		p := types.Poster{Key: key, Size: size}
+		var fetchedAt int64
+		row := tx.QueryRow(
+			`SELECT content_type, data, source_url, fetched_at FROM posters
+				WHERE movie_title = ? AND release_year = ? AND director = ? AND size = ?`,
+			key.Title,
+			key.ReleaseYear,
+			key.Director,
+			size,
+		)
+		err := row.Scan(&p.ContentType, &p.Data, &p.SourceUrl, &fetchedAt)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		p.FetchedAt = time.Unix(fetchedAt, 0)
+		poster = &p
+		return nil
+	})
+	return poster, err
+}
+
+func LoadMovieMetadataPins(db *sql.DB, log logging.Logger) ([]types.MovieMetadataPin, error) {
+	log.Debugf("Querying movie metadata pins")
+	
+	var pins []types.MovieMetadataPin
+	err := transaction(db, func (tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT movie_title, release_year, director, imdb_id, updated_by, updated_at FROM movie_info_pins ORDER BY movie_title, release_year")
+		if err != nil {
+			return err
+		}
+		
+		return forEachRow(rows, func (rows *sql.Rows) error {
+			var p types.MovieMetadataPin
+			var updatedAt int64
+			if err := rows.Scan(&p.Key.Title, &p.Key.ReleaseYear, &p.Key.Director, &p.ImdbId, &p.UpdatedBy, &updatedAt); err != nil {
+				return err
+			}
+			p.UpdatedAt = time.Unix(updatedAt, 0)
+			pins = append(pins, p)
+			return nil
+		})
+	})
+	return pins, err
+}
+
+func LoadCoordinateOverrides(db *sql.DB, log logging.Logger) ([]types.CoordinateOverride, error) {
+	log.Debugf("Querying coordinate overrides")
+	
+	var overrides []types.CoordinateOverride
+	err := transaction(db, func (tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT location_name, lat, lng, note, updated_by, updated_at FROM coordinate_overrides ORDER BY location_name")
+		if err != nil {
+			return err
+		}
+		
+		return forEachRow(rows, func (rows *sql.Rows) error {
+			var o types.CoordinateOverride
+			var updatedAt int64
+			if err := rows.Scan(&o.LocationName, &o.Coordinates.Lat, &o.Coordinates.Lng, &o.Note, &o.UpdatedBy, &updatedAt); err != nil {
+				return err
+			}
+			o.UpdatedAt = time.Unix(updatedAt, 0)
+			overrides = append(overrides, o)
+			return nil
+		})
+	})
+	return overrides, err
+}
+
+// Load the most recent changes to coordinate overrides (newest first).
+func LoadCoordinateOverrideAudit(db *sql.DB, limit int, log logging.Logger) ([]types.CoordinateOverrideChange, error) {
+	log.Debugf("Querying the %d most recent coordinate override changes", limit)
+	
+	var changes []types.CoordinateOverrideChange
+	err := transaction(db, func (tx *sql.Tx) error {
+		rows, err := tx.Query(
+			"SELECT location_name, action, lat, lng, note, user, changed_at FROM coordinate_override_audit ORDER BY id DESC LIMIT ?",
+			limit,
+		)
+		if err != nil {
+			return err
+		}
+		
+		return forEachRow(rows, func (rows *sql.Rows) error {
+			var c types.CoordinateOverrideChange
+			var changedAt int64
+			if err := rows.Scan(&c.LocationName, &c.Action, &c.Coordinates.Lat, &c.Coordinates.Lng, &c.Note, &c.User, &changedAt); err != nil {
+				return err
+			}
+			c.ChangedAt = time.Unix(changedAt, 0)
+			changes = append(changes, c)
+			return nil
+		})
+	})
+	return changes, err
+}
+
+const geocodeReviewColumns = "location_name, reason, candidates_json, status, created_at, resolved_by, resolved_at"
+
+func scanGeocodeReview(rows *sql.Rows) (types.GeocodeReview, error) {
+	var r types.GeocodeReview
+	var candidatesJson string
+	var createdAt int64
+	var resolvedAt int64
+	if err := rows.Scan(&r.LocationName, &r.Reason, &candidatesJson, &r.Status, &createdAt, &r.ResolvedBy, &resolvedAt); err != nil {
+		return r, err
+	}
+	r.CreatedAt = time.Unix(createdAt, 0)
+	if resolvedAt != 0 {
+		r.ResolvedAt = time.Unix(resolvedAt, 0)
+	}
+	return r, json.Unmarshal([]byte(candidatesJson), &r.Candidates)
+}
+
+// Load the oldest geocode reviews with the given status.
+func LoadGeocodeReviews(db *sql.DB, status string, limit int, log logging.Logger) ([]types.GeocodeReview, error) {
+	log.Debugf("Querying geocode reviews with status '%s'", status)
+	
+	var reviews []types.GeocodeReview
+	err := transaction(db, func (tx *sql.Tx) error {
+		rows, err := tx.Query(
+			"SELECT " + geocodeReviewColumns + " FROM geocode_reviews WHERE status = ? ORDER BY created_at, location_name LIMIT ?",
+			status,
+			limit,
+		)
+		if err != nil {
+			return err
+		}
+		
+		return forEachRow(rows, func (rows *sql.Rows) error {
+			r, err := scanGeocodeReview(rows)
+			if err != nil {
+				return err
+			}
+			reviews = append(reviews, r)
+			return nil
+		})
+	})
+	return reviews, err
+}
+
+// Load the rejected reviews of the geocodes of the given location names (by name).
+func LoadRejectedGeocodeReviews(db *sql.DB, locNames []string, log logging.Logger) (map[string]types.GeocodeReview, error) {
+	reviews := make(map[string]types.GeocodeReview)
+	if len(locNames) == 0 {
+		return reviews, nil
+	}
+	
+	args := make([]interface{}, 0, len(locNames) + 1)
+	args = append(args, types.ReviewRejected)
+	for _, n := range locNames {
+		args = append(args, n)
+	}
+	
+	stmt := "SELECT " + geocodeReviewColumns + " FROM geocode_reviews WHERE status = ? AND location_name IN " + fancyRepeat("(", "?", len(locNames), ", ", ")")
+	rows, err := db.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	
+	err = forEachRow(rows, func (rows *sql.Rows) error {
+		r, err := scanGeocodeReview(rows)
+		if err != nil {
+			return err
+		}
+		reviews[r.LocationName] = r
+		return nil
+	})
+	return reviews, err
+}
+
+// Load the review of the geocode of a location name (nil if there is none).
+func LoadGeocodeReview(db *sql.DB, locName string, log logging.Logger) (*types.GeocodeReview, error) {
+	var review *types.GeocodeReview
+	err := transaction(db, func (tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT " + geocodeReviewColumns + " FROM geocode_reviews WHERE location_name = ?", locName)
+		if err != nil {
+			return err
+		}
+		
+		return forEachRow(rows, func (rows *sql.Rows) error {
+			r, err := scanGeocodeReview(rows)
+			review = &r
+			return err
+		})
+	})
+	return review, err
+}
@@ -106,17 +106,369 @@ func InitTables(tx *sql.Tx, log logging.Logger) error {
 	}
 	
 	log.Infof("Creating table 'movie_info' unless it already exists")
-	// As the table is intended to act as a cache that survives updates, `movie_title` is not constrained to reference
-	// an actual movie title.
+	// As the table is intended to act as a cache that survives updates, the movie identity
+	// (`movie_title`, `release_year`, `director`) is not constrained to reference an actual movie.
 	_, err = tx.Exec(
 		`CREATE TABLE IF NOT EXISTS movie_info (
-			movie_title VARCHAR(255) PRIMARY KEY,
-			info_json   TEXT
+			movie_title  VARCHAR(255),
+			release_year INT UNSIGNED,
+			director     VARCHAR(255),
+			info_json    TEXT,
+			
+			PRIMARY KEY (movie_title, release_year, director)
+		)`,
+	)
+	if err != nil {
+		return err
+	}
+	
+	return migrateTables(tx, log)
+}
+
+const (
+	genresTable    = "movie_info_genres"
+	languagesTable = "movie_info_languages"
+	countriesTable = "movie_info_countries"
+)
+
+var movieInfoListTables = []string{genresTable, languagesTable, countriesTable}
+
+// Bring tables created by earlier versions of the application up to date.
+func MigrateTables(db *sql.DB, log logging.Logger) error {
+	return transaction(db, func (tx *sql.Tx) error {
+		return migrateTables(tx, log)
+	})
+}
+
+func migrateTables(tx *sql.Tx, log logging.Logger) error {
+	var err error
+	
+	log.Infof("Creating table 'location_aliases' unless it already exists")
+	// As the table is intended to keep merge decisions across updates, neither `alias` nor `canonical` are constrained
+	// to reference an actual location name.
+	_, err = tx.Exec(
+		`CREATE TABLE IF NOT EXISTS location_aliases (
+			alias     VARCHAR(255) PRIMARY KEY,
+			canonical VARCHAR(255) NOT NULL,
+			score     FLOAT NOT NULL,
+			status    VARCHAR(16) NOT NULL
+		)`,
+	)
+	if err != nil {
+		return err
+	}
+	
+	log.Infof("Creating table 'geocode_failures' unless it already exists")
+	// Negative entries of the coordinates cache. Times are stored as Unix timestamps.
+	_, err = tx.Exec(
+		`CREATE TABLE IF NOT EXISTS geocode_failures (
+			location_name VARCHAR(255) PRIMARY KEY,
+			reason        VARCHAR(255) NOT NULL,
+			attempts      INT UNSIGNED NOT NULL,
+			failed_at     BIGINT NOT NULL,
+			retry_at      BIGINT NOT NULL
+		)`,
+	)
+	if err != nil {
+		return err
+	}
+	
+	log.Infof("Creating table 'geocode_job' unless it already exists")
+	// Single row with the checkpoint of the background geocoding job. Times are stored as Unix timestamps (0 if unset).
+	_, err = tx.Exec(
+		`CREATE TABLE IF NOT EXISTS geocode_job (
+			id          TINYINT UNSIGNED PRIMARY KEY,
+			cursor_name VARCHAR(255) NOT NULL,
+			total       INT UNSIGNED NOT NULL,
+			processed   INT UNSIGNED NOT NULL,
+			resolved    INT UNSIGNED NOT NULL,
+			failed      INT UNSIGNED NOT NULL,
+			started_at  BIGINT NOT NULL,
+			updated_at  BIGINT NOT NULL,
+			finished_at BIGINT NOT NULL
+		)`,
+	)
+	if err != nil {
+		return err
+	}
+	
+	log.Infof("Creating table 'geocode_reviews' unless it already exists")
+	// Candidates are stored as a JSON array of `types.Geocode`. Times are stored as Unix timestamps (0 if unset).
+	_, err = tx.Exec(
+		`CREATE TABLE IF NOT EXISTS geocode_reviews (
+			location_name   VARCHAR(255) PRIMARY KEY,
+			reason          VARCHAR(64) NOT NULL,
+			candidates_json TEXT NOT NULL,
+			status          VARCHAR(16) NOT NULL,
+			created_at      BIGINT NOT NULL,
+			resolved_by     VARCHAR(255) NOT NULL,
+			resolved_at     BIGINT NOT NULL
+		)`,
+	)
+	if err != nil {
+		return err
+	}
+	
+	log.Infof("Creating table 'coordinate_overrides' unless it already exists")
+	// Kept apart from the coordinates cache such that overrides survive when the cache is rebuilt. As for the cache,
+	// `location_name` is not constrained to reference an actual location name.
+	_, err = tx.Exec(
+		`CREATE TABLE IF NOT EXISTS coordinate_overrides (
+			location_name VARCHAR(255) PRIMARY KEY,
+			lat           FLOAT(10, 6) NOT NULL,
+			lng           FLOAT(10, 6) NOT NULL,
+			note          VARCHAR(255) NOT NULL,
+			updated_by    VARCHAR(255) NOT NULL,
+			updated_at    BIGINT NOT NULL
+		)`,
+	)
+	if err != nil {
+		return err
+	}
+	
+	log.Infof("Creating table 'coordinate_override_audit' unless it already exists")
+	_, err = tx.Exec(
+		`CREATE TABLE IF NOT EXISTS coordinate_override_audit (
+			id            INT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+			location_name VARCHAR(255) NOT NULL,
+			action        VARCHAR(16) NOT NULL,
+			lat           FLOAT(10, 6) NOT NULL,
+			lng           FLOAT(10, 6) NOT NULL,
+			note          VARCHAR(255) NOT NULL,
+			user          VARCHAR(255) NOT NULL,
+			changed_at    BIGINT NOT NULL
+		)`,
+	)
+	if err != nil {
+		return err
+	}
+	
+	// Tables created before movies were identified by more than their title are keyed by title only. The info is
+	// attributed to the only movie with that title; info of titles shared by several movies (like remakes) cannot be
+	// attributed reliably, so it's dropped and fetched again on the next update.
+	keyedByIdentity, err := columnExists(tx, "movie_info", "release_year")
+	if err != nil {
+		return err
+	}
+	if !keyedByIdentity {
+		log.Infof("Migrating table 'movie_info' to be keyed by movie title, release year, and director")
+		_, err := tx.Exec(
+			`ALTER TABLE movie_info
+				ADD COLUMN release_year INT UNSIGNED NOT NULL AFTER movie_title,
+				ADD COLUMN director VARCHAR(255) NOT NULL AFTER release_year,
+				DROP PRIMARY KEY`,
+		)
+		if err != nil {
+			return err
+		}
+		
+		res, err := tx.Exec(
+			`DELETE FROM movie_info WHERE movie_title IN (
+				SELECT title FROM movies GROUP BY title HAVING COUNT(DISTINCT release_year, director) > 1
+			)`,
+		)
+		if err != nil {
+			return err
+		}
+		dropped, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		log.Infof("Dropped the cached info of %d movie titles that are shared by several movies", dropped)
+		
+		_, err = tx.Exec(
+			`UPDATE movie_info i JOIN (
+				SELECT title, COALESCE(MIN(release_year), 0) AS release_year, COALESCE(MIN(director), '') AS director
+				FROM movies GROUP BY title
+			) m ON m.title = i.movie_title
+			SET i.release_year = m.release_year, i.director = m.director`,
+		)
+		if err != nil {
+			return err
+		}
+		
+		if _, err := tx.Exec("ALTER TABLE movie_info ADD PRIMARY KEY (movie_title, release_year, director)"); err != nil {
+			return err
+		}
+	}
+	
+	// Provider of the metadata in `info_json` (empty for raw OMDB responses cached by earlier versions, which are
+	// converted by `data.Init`).
+	err = addColumnsUnlessExist(tx, "movie_info", []string{
+		"provider VARCHAR(32) NOT NULL DEFAULT ''",
+	}, log)
+	if err != nil {
+		return err
+	}
+	
+	// Times (as Unix timestamps) when the info was fetched and when it's to be refreshed. Info cached before they were
+	// recorded has expired.
+	err = addColumnsUnlessExist(tx, "movie_info", []string{
+		"fetched_at BIGINT NOT NULL DEFAULT 0",
+		"expires_at BIGINT NOT NULL DEFAULT 0",
+	}, log)
+	if err != nil {
+		return err
+	}
+	
+	// Fields of `types.MovieMetadata` (which were kept in `info_json` by earlier versions and are converted by
+	// `data.Init`). The lists are kept in the tables below.
+	err = addColumnsUnlessExist(tx, "movie_info", []string{
+		"found           TINYINT(1) NOT NULL DEFAULT 0",
+		"provider_id     VARCHAR(64) NOT NULL DEFAULT ''",
+		"imdb_id         VARCHAR(16) NOT NULL DEFAULT ''",
+		"title           VARCHAR(255) NOT NULL DEFAULT ''",
+		"year            INT UNSIGNED NOT NULL DEFAULT 0",
+		"released        VARCHAR(32) NOT NULL DEFAULT ''",
+		"runtime_minutes INT UNSIGNED NOT NULL DEFAULT 0",
+		"rated           VARCHAR(16) NOT NULL DEFAULT ''",
+		"plot            VARCHAR(1024) NOT NULL DEFAULT ''",
+		"awards          VARCHAR(255) NOT NULL DEFAULT ''",
+		"poster_url      VARCHAR(512) NOT NULL DEFAULT ''",
+		"metascore       INT UNSIGNED NOT NULL DEFAULT 0",
+		"imdb_rating     FLOAT NOT NULL DEFAULT 0",
+		"imdb_votes      INT UNSIGNED NOT NULL DEFAULT 0",
+	}, log)
+	if err != nil {
+		return err
+	}
+	
+	// Lists of the movie metadata in the order given by the provider. As for `movie_info`, the movie identity is not
+	// constrained to reference an actual movie.
+	for _, table := range movieInfoListTables {
+		log.Infof("Creating table '%s' unless it already exists", table)
+		_, err = tx.Exec(
+			`CREATE TABLE IF NOT EXISTS ` + table + ` (
+				movie_title  VARCHAR(255) NOT NULL,
+				release_year INT UNSIGNED NOT NULL,
+				director     VARCHAR(255) NOT NULL,
+				position     INT UNSIGNED NOT NULL,
+				name         VARCHAR(255) NOT NULL,
+				
+				KEY (movie_title, release_year, director),
+				KEY (name)
+			)`,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	
+	// Score of the match between the movie and the one found by the provider (see `types.MovieMetadata`).
+	err = addColumnsUnlessExist(tx, "movie_info", []string{
+		"match_confidence FLOAT NOT NULL DEFAULT 0",
+	}, log)
+	if err != nil {
+		return err
+	}
+	
+	log.Infof("Creating table 'movie_info_pins' unless it already exists")
+	_, err = tx.Exec(
+		`CREATE TABLE IF NOT EXISTS movie_info_pins (
+			movie_title  VARCHAR(255) NOT NULL,
+			release_year INT UNSIGNED NOT NULL,
+			director     VARCHAR(255) NOT NULL,
+			imdb_id      VARCHAR(16) NOT NULL,
+			updated_by   VARCHAR(255) NOT NULL,
+			updated_at   BIGINT NOT NULL,
+			
+			PRIMARY KEY (movie_title, release_year, director)
 		)`,
 	)
 	if err != nil {
 		return err
 	}
 	
+	log.Infof("Creating table 'posters' unless it already exists")
+	// Thumbnails of posters by size (see `types.Poster`). As for `movie_info`, the movie identity is not constrained to
+	// reference an actual movie.
+	_, err = tx.Exec(
+		`CREATE TABLE IF NOT EXISTS posters (
+			movie_title  VARCHAR(255) NOT NULL,
+			release_year INT UNSIGNED NOT NULL,
+			director     VARCHAR(255) NOT NULL,
+			size         VARCHAR(16) NOT NULL,
+			content_type VARCHAR(32) NOT NULL,
+			data         MEDIUMBLOB NOT NULL,
+			source_url   VARCHAR(512) NOT NULL,
+			fetched_at   BIGINT NOT NULL,
+			
+			PRIMARY KEY (movie_title, release_year, director, size)
+		)`,
+	)
+	if err != nil {
+		return err
+	}
+	
+	log.Infof("Creating table 'movie_info_credits' unless it already exists")
+	// Credits by role ("director", "writer", or "actor").
+	_, err = tx.Exec(
+		`CREATE TABLE IF NOT EXISTS movie_info_credits (
+			movie_title  VARCHAR(255) NOT NULL,
+			release_year INT UNSIGNED NOT NULL,
+			director     VARCHAR(255) NOT NULL,
+			role         VARCHAR(16) NOT NULL,
+			position     INT UNSIGNED NOT NULL,
+			name         VARCHAR(255) NOT NULL,
+			
+			KEY (movie_title, release_year, director),
+			KEY (name)
+		)`,
+	)
+	if err != nil {
+		return err
+	}
+	
+	log.Infof("Creating table 'stamps' unless it already exists")
+	// Times (as Unix timestamps in nanoseconds) of events that later requests depend on, like the import of the seed or
+	// the last change of the data.
+	_, err = tx.Exec(
+		`CREATE TABLE IF NOT EXISTS stamps (
+			name       VARCHAR(64) PRIMARY KEY,
+			stamped_at BIGINT NOT NULL
+		)`,
+	)
+	if err != nil {
+		return err
+	}
+	
+	// Name of the geocoder that found the coordinates (empty for coordinates cached before it was recorded).
+	err = addColumnsUnlessExist(tx, "coordinates", []string{
+		"source VARCHAR(32) NOT NULL DEFAULT ''",
+	}, log)
+	if err != nil {
+		return err
+	}
+	
+	// Details of the match (see `types.Geocode`). Place types are comma separated.
+	err = addColumnsUnlessExist(tx, "coordinates", []string{
+		"formatted_address VARCHAR(255) NOT NULL DEFAULT ''",
+		"location_type     VARCHAR(32) NOT NULL DEFAULT ''",
+		"place_types       VARCHAR(255) NOT NULL DEFAULT ''",
+		"viewport_south    FLOAT(10, 6) NOT NULL DEFAULT 0",
+		"viewport_west     FLOAT(10, 6) NOT NULL DEFAULT 0",
+		"viewport_north    FLOAT(10, 6) NOT NULL DEFAULT 0",
+		"viewport_east     FLOAT(10, 6) NOT NULL DEFAULT 0",
+		"matched_query     VARCHAR(255) NOT NULL DEFAULT ''",
+	}, log)
+	if err != nil {
+		return err
+	}
+	
+	// Structured parts of the location name (see `fetch.ParseLocationName`).
+	err = addColumnsUnlessExist(tx, "locations", []string{
+		"landmark     VARCHAR(255) NOT NULL DEFAULT ''",
+		"address      VARCHAR(255) NOT NULL DEFAULT ''",
+		"street_1     VARCHAR(255) NOT NULL DEFAULT ''",
+		"street_2     VARCHAR(255) NOT NULL DEFAULT ''",
+		"neighborhood VARCHAR(255) NOT NULL DEFAULT ''",
+		"range_street VARCHAR(255) NOT NULL DEFAULT ''",
+		"range_from   VARCHAR(255) NOT NULL DEFAULT ''",
+		"range_to     VARCHAR(255) NOT NULL DEFAULT ''",
+	}, log)
+	if err != nil {
+		return err
+	}
+	
 	return nil
 }
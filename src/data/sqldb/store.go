@@ -5,6 +5,9 @@ import (
 	"src/logging"
 	"src/watch"
 	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
 )
 
 func InitTablesAndStoreMovies(db *sql.DB, movies []types.Movie, log logging.Logger) error {
@@ -16,7 +19,7 @@ func InitTablesAndStoreMovies(db *sql.DB, movies []types.Movie, log logging.Logg
 		if err := StoreMovies(tx, movies, log); err != nil {
 			return err
 		}
-		return nil
+		return touchDataUpdated(tx)
 	})
 }
 
@@ -42,18 +45,27 @@ func StoreMovies(tx *sql.Tx, movies []types.Movie, log logging.Logger) error {
 	log.Infof("Inserted %d movies in %d ms", len(movies), sw.ElapsedTimeMillis(true))
 	
 	// Query movies in order to get their IDs.
-	movieTitleIdMap, err := loadMovieTitleIdMap(tx)
+	movieKeyIdMap, err := loadMovieKeyIdMap(tx)
 	if err != nil {
 		return err
 	}
 	
 	// Bulk insert locations.
-	locationInserter := NewBulkInserter(4)
+	locationInserter := NewColumnBulkInserter(
+		"movie_id", "name", "fun_fact",
+		"landmark", "address", "street_1", "street_2",
+		"neighborhood", "range_street", "range_from", "range_to",
+	)
 	locationCount := 0
 	for _, movie := range movies {
-		id := movieTitleIdMap[movie.Title]
+		id := movieKeyIdMap[movie.Key()]
 		for _, loc := range movie.Locations {
-			locationInserter.Add(nil, id, loc.Name, loc.FunFact)
+			p := loc.Parts
+			locationInserter.Add(
+				id, loc.Name, loc.FunFact,
+				p.Landmark, p.Address, p.Intersection[0], p.Intersection[1],
+				p.Neighborhood, p.Range.Street, p.Range.From, p.Range.To,
+			)
 			locationCount++
 		}
 	}
@@ -93,7 +105,7 @@ func StoreMovies(tx *sql.Tx, movies []types.Movie, log logging.Logger) error {
 	movieActorInserter := NewBulkInserter(2)
 	movieActorCount := 0
 	for _, movie := range movies {
-		movieId := movieTitleIdMap[movie.Title]
+		movieId := movieKeyIdMap[movie.Key()]
 		for _, actorName := range movie.Actors {
 			actorId := actorIdMap[actorName]
 			movieActorInserter.Add(movieId, actorId)
@@ -112,23 +124,23 @@ func StoreMovies(tx *sql.Tx, movies []types.Movie, log logging.Logger) error {
 	return nil
 }
 
-func loadMovieTitleIdMap(tx *sql.Tx) (map[string]int64, error) {
-	rows, err := tx.Query("SELECT title, id FROM movies")
+func loadMovieKeyIdMap(tx *sql.Tx) (map[types.MovieKey]int64, error) {
+	rows, err := tx.Query("SELECT title, release_year, director, id FROM movies")
 	if err != nil {
 		return nil, err
 	}
 	
-	movieTitleIdMap := make(map[string]int64)
+	movieKeyIdMap := make(map[types.MovieKey]int64)
 	err = forEachRow(rows, func (rows *sql.Rows) error {
-		var title string
+		var key types.MovieKey
 		var id int64
-		if err := rows.Scan(&title, &id); err != nil {
+		if err := rows.Scan(&key.Title, &key.ReleaseYear, &key.Director, &id); err != nil {
 			return err
 		}
-		movieTitleIdMap[title] = id
+		movieKeyIdMap[key] = id
 		return nil
 	})
-	return movieTitleIdMap, err
+	return movieKeyIdMap, err
 }
 
 func loadActorIdMap(tx *sql.Tx) (map[string]int64, error) {
@@ -150,7 +162,22 @@ func loadActorIdMap(tx *sql.Tx) (map[string]int64, error) {
 	return actorIdMap, err
 }
 
-func StoreMovieInfo(db *sql.DB, movieInfo map[string]string, log logging.Logger) error {
+// Delete the raw OMDB responses cached by earlier versions that are left after converting them.
+func DeleteLegacyMovieInfo(db *sql.DB, log logging.Logger) error {
+	return transaction(db, func (tx *sql.Tx) error {
+		res, err := tx.Exec("DELETE FROM movie_info WHERE provider = ''")
+		if err != nil {
+			return err
+		}
+		count, err := res.RowsAffected()
+		log.Infof("Deleted %d legacy movie infos", count)
+		return err
+	})
+}
+
+// Store (or replace) cached movie metadata. Movies that weren't found by the provider are stored without metadata such
+// that they aren't looked up again until the entry expires.
+func StoreMovieMetadata(db *sql.DB, movieInfo []types.CachedMovieMetadata, log logging.Logger) error {
 	if len(movieInfo) == 0 {
 		return nil
 	}
@@ -160,13 +187,97 @@ func StoreMovieInfo(db *sql.DB, movieInfo map[string]string, log logging.Logger)
 	log.Infof("Inserting %d movie infos into database", len(movieInfo))
 	
 	err := transaction(db, func (tx *sql.Tx) error {
-		inserter := NewBulkInserter(2)
+		keys := make([]types.MovieKey, len(movieInfo))
+		for i, c := range movieInfo {
+			keys[i] = c.Key
+		}
+		if err := deleteMovieMetadataLists(tx, keys); err != nil {
+			return err
+		}
 		
-		for t, i := range movieInfo {
-			inserter.Add(t, i)
+		inserter := NewColumnBulkInserter(
+			"movie_title",
+			"release_year",
+			"director",
+			"info_json",
+			"provider",
+			"fetched_at",
+			"expires_at",
+			"found",
+			"provider_id",
+			"imdb_id",
+			"title",
+			"year",
+			"released",
+			"runtime_minutes",
+			"rated",
+			"plot",
+			"awards",
+			"poster_url",
+			"metascore",
+			"imdb_rating",
+			"imdb_votes",
+			"match_confidence",
+		)
+		listInserters := make(map[string]*BulkInsertStmtBuilder)
+		for _, table := range movieInfoListTables {
+			listInserter := NewBulkInserter(5)
+			listInserters[table] = &listInserter
 		}
+		creditInserter := NewBulkInserter(6)
 		
-		if _, err := inserter.Exec(tx, "movie_info", nil); err != nil {
+		for _, c := range movieInfo {
+			k := c.Key
+			m := c.Metadata
+			if m == nil {
+				m = &types.MovieMetadata{}
+			}
+			inserter.Add(
+				k.Title,
+				k.ReleaseYear,
+				k.Director,
+				"",
+				c.Provider,
+				unixTime(c.FetchedAt),
+				unixTime(c.ExpiresAt),
+				c.Metadata != nil,
+				truncate(m.ProviderId, 64),
+				truncate(m.ImdbId, 16),
+				truncate(m.Title, 255),
+				m.Year,
+				truncate(m.Released, 32),
+				m.RuntimeMinutes,
+				truncate(m.Rated, 16),
+				truncate(m.Plot, 1024),
+				truncate(m.Awards, 255),
+				truncate(m.PosterUrl, 512),
+				m.Metascore,
+				m.ImdbRating,
+				m.ImdbVotes,
+				m.MatchConfidence,
+			)
+			
+			for table, values := range map[string][]string{genresTable: m.Genres, languagesTable: m.Languages, countriesTable: m.Countries} {
+				for i, v := range values {
+					listInserters[table].Add(k.Title, k.ReleaseYear, k.Director, i, truncate(v, 255))
+				}
+			}
+			for role, names := range map[string][]string{types.CreditDirector: m.Directors, types.CreditWriter: m.Writers, types.CreditActor: m.Actors} {
+				for i, n := range names {
+					creditInserter.Add(k.Title, k.ReleaseYear, k.Director, role, i, truncate(n, 255))
+				}
+			}
+		}
+		
+		if _, err := inserter.ExecReplace(tx, "movie_info", nil); err != nil {
+			return err
+		}
+		for table, listInserter := range listInserters {
+			if _, err := listInserter.Exec(tx, table, nil); err != nil {
+				return err
+			}
+		}
+		if _, err := creditInserter.Exec(tx, "movie_info_credits", nil); err != nil {
 			return err
 		}
 		
@@ -180,7 +291,26 @@ func StoreMovieInfo(db *sql.DB, movieInfo map[string]string, log logging.Logger)
 	return nil
 }
 
-func StoreCoordinates(db *sql.DB, lc map[string]*types.Coordinates, log logging.Logger) error {
+func deleteMovieMetadataLists(tx *sql.Tx, keys []types.MovieKey) error {
+	condition, args := movieKeysCondition(keys)
+	for _, table := range append(movieInfoListTables, "movie_info_credits") {
+		if _, err := tx.Exec("DELETE FROM " + table + " WHERE " + condition, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Condition matching the rows of the given movies (by the columns `movie_title`, `release_year`, and `director`).
+func movieKeysCondition(keys []types.MovieKey) (string, []interface{}) {
+	args := make([]interface{}, 0, 3 * len(keys))
+	for _, k := range keys {
+		args = append(args, k.Title, k.ReleaseYear, k.Director)
+	}
+	return "(movie_title, release_year, director) IN " + fancyRepeat("(", "(?, ?, ?)", len(keys), ", ", ")"), args
+}
+
+func StoreCoordinates(db *sql.DB, lc map[string]*types.Geocode, log logging.Logger) error {
 	if len(lc) == 0 {
 		return nil
 	}
@@ -190,18 +320,23 @@ func StoreCoordinates(db *sql.DB, lc map[string]*types.Coordinates, log logging.
 	log.Infof("Inserting %d location coordinates into database", len(lc))
 	
 	err := transaction(db, func (tx *sql.Tx) error {
-		inserter := NewBulkInserter(3)
+		inserter := newGeocodeInserter()
 		
-		for n, c := range lc {
-			if c == nil {
+		for n, g := range lc {
+			if g == nil {
 				continue
 			}
 			
-			inserter.Add(n, c.Lat, c.Lng)
+			addGeocode(&inserter, n, g)
 		}
 		
-		_, err := inserter.Exec(tx, "coordinates", nil)
-		return err
+		// Coordinates may have been stored concurrently by another request or the background job (which found the same
+		// ones). Replacing them also replaces cached coordinates that were ignored when loading (like ones outside of
+		// San Francisco).
+		if _, err := inserter.ExecReplace(tx, "coordinates", nil); err != nil {
+			return err
+		}
+		return touchDataUpdated(tx)
 	})
 	if err != nil {
 		return err
@@ -210,3 +345,388 @@ func StoreCoordinates(db *sql.DB, lc map[string]*types.Coordinates, log logging.
 	log.Infof("Inserted %d location coordinate pairs in %d ms", len(lc), sw.TotalElapsedTimeMillis())
 	return nil
 }
+
+func newGeocodeInserter() BulkInsertStmtBuilder {
+	return NewColumnBulkInserter(
+		"location_name",
+		"lat",
+		"lng",
+		"source",
+		"formatted_address",
+		"location_type",
+		"place_types",
+		"viewport_south",
+		"viewport_west",
+		"viewport_north",
+		"viewport_east",
+		"matched_query",
+	)
+}
+
+func addGeocode(inserter *BulkInsertStmtBuilder, locName string, g *types.Geocode) {
+	inserter.Add(
+		locName,
+		g.Coordinates.Lat,
+		g.Coordinates.Lng,
+		g.Source,
+		truncate(g.FormattedAddress, 255),
+		g.LocationType,
+		truncate(strings.Join(g.PlaceTypes, ","), 255),
+		g.Viewport.South,
+		g.Viewport.West,
+		g.Viewport.North,
+		g.Viewport.East,
+		truncate(g.Query, 255),
+	)
+}
+
+// Store proposed location name merges. Proposals for aliases that already exist are ignored such that decisions made
+// by an admin are kept.
+func StoreLocationMerges(db *sql.DB, merges []types.LocationMerge, log logging.Logger) error {
+	if len(merges) == 0 {
+		return nil
+	}
+	
+	sw := watch.NewStopWatch()
+	
+	log.Infof("Inserting %d location name merges into database", len(merges))
+	
+	err := transaction(db, func (tx *sql.Tx) error {
+		inserter := NewBulkInserter(4)
+		
+		for _, m := range merges {
+			inserter.Add(m.Alias, m.Canonical, m.Score, m.Status)
+		}
+		
+		if _, err := inserter.ExecIgnore(tx, "location_aliases", nil); err != nil {
+			return err
+		}
+		return touchDataUpdated(tx)
+	})
+	if err != nil {
+		return err
+	}
+	
+	log.Infof("Inserted location name merges in %d ms", sw.TotalElapsedTimeMillis())
+	return nil
+}
+
+func SetLocationMergeStatus(db *sql.DB, alias string, status string, log logging.Logger) error {
+	log.Infof("Setting status of location name merge for alias '%s' to '%s'", alias, status)
+	
+	return transaction(db, func (tx *sql.Tx) error {
+		if _, err := tx.Exec("UPDATE location_aliases SET status = ? WHERE alias = ?", status, alias); err != nil {
+			return err
+		}
+		return touchDataUpdated(tx)
+	})
+}
+
+// Store (or replace) negative entries of the coordinates cache.
+func StoreGeocodeFailures(db *sql.DB, failures []types.GeocodeFailure, log logging.Logger) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	
+	log.Infof("Inserting %d geocode failures into database", len(failures))
+	
+	return transaction(db, func (tx *sql.Tx) error {
+		inserter := NewBulkInserter(5)
+		
+		for _, f := range failures {
+			inserter.Add(truncate(f.LocationName, 255), truncate(f.Reason, 255), f.Attempts, f.FailedAt.Unix(), f.RetryAt.Unix())
+		}
+		
+		_, err := inserter.ExecReplace(tx, "geocode_failures", nil)
+		return err
+	})
+}
+
+// Delete the negative entries of the given location names or all of them if `locNames` is nil.
+func ClearGeocodeFailures(db *sql.DB, locNames []string, log logging.Logger) (int64, error) {
+	if locNames != nil && len(locNames) == 0 {
+		return 0, nil
+	}
+	
+	var count int64
+	err := transaction(db, func (tx *sql.Tx) error {
+		var res sql.Result
+		var err error
+		if locNames == nil {
+			log.Infof("Clearing all geocode failures")
+			res, err = tx.Exec("DELETE FROM geocode_failures")
+		} else {
+			log.Infof("Clearing geocode failures of %d location names", len(locNames))
+			args := make([]interface{}, len(locNames))
+			for i, n := range locNames {
+				args[i] = n
+			}
+			res, err = tx.Exec("DELETE FROM geocode_failures WHERE location_name IN " + fancyRepeat("(", "?", len(args), ", ", ")"), args...)
+		}
+		if err != nil {
+			return err
+		}
+		count, err = res.RowsAffected()
+		return err
+	})
+	return count, err
+}
+
+// Queue ambiguous geocodes for review. Reviews of location names that have been queued before are ignored such that
+// decisions made by an admin are kept.
+func StoreGeocodeReviews(db *sql.DB, reviews []types.GeocodeReview, log logging.Logger) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+	
+	log.Infof("Queuing %d geocodes for review", len(reviews))
+	
+	return transaction(db, func (tx *sql.Tx) error {
+		// A new result of a location name whose candidates were rejected is reviewed again, while pending and resolved
+		// reviews are kept.
+		args := make([]interface{}, 0, len(reviews) + 1)
+		args = append(args, types.ReviewRejected)
+		for _, r := range reviews {
+			args = append(args, truncate(r.LocationName, 255))
+		}
+		stmt := "DELETE FROM geocode_reviews WHERE status = ? AND location_name IN " + fancyRepeat("(", "?", len(reviews), ", ", ")")
+		if _, err := tx.Exec(stmt, args...); err != nil {
+			return err
+		}
+		
+		inserter := NewBulkInserter(7)
+		
+		for _, r := range reviews {
+			candidatesJson, err := json.Marshal(r.Candidates)
+			if err != nil {
+				return err
+			}
+			inserter.Add(truncate(r.LocationName, 255), r.Reason, string(candidatesJson), r.Status, r.CreatedAt.Unix(), "", 0)
+		}
+		
+		_, err := inserter.ExecIgnore(tx, "geocode_reviews", nil)
+		return err
+	})
+}
+
+// Resolve the review of a geocode by replacing the cached coordinates of the location name with `geocode` or, if it's
+// nil (i.e. all candidates were rejected), by deleting them.
+func ResolveGeocodeReview(db *sql.DB, locName string, geocode *types.Geocode, user string, now time.Time, log logging.Logger) error {
+	status := types.ReviewResolved
+	if geocode == nil {
+		status = types.ReviewRejected
+	}
+	log.Infof("Setting status of geocode review of location '%s' to '%s'", locName, status)
+	
+	return transaction(db, func (tx *sql.Tx) error {
+		if geocode == nil {
+			if _, err := tx.Exec("DELETE FROM coordinates WHERE location_name = ?", locName); err != nil {
+				return err
+			}
+		} else {
+			inserter := newGeocodeInserter()
+			addGeocode(&inserter, locName, geocode)
+			if _, err := inserter.ExecReplace(tx, "coordinates", nil); err != nil {
+				return err
+			}
+		}
+		
+		_, err := tx.Exec(
+			"UPDATE geocode_reviews SET status = ?, resolved_by = ?, resolved_at = ? WHERE location_name = ?",
+			status,
+			truncate(user, 255),
+			now.Unix(),
+			locName,
+		)
+		if err != nil {
+			return err
+		}
+		return touchDataUpdated(tx)
+	})
+}
+
+// Set (or replace) coordinate overrides and record the changes in the audit log.
+func StoreCoordinateOverrides(db *sql.DB, overrides []types.CoordinateOverride, log logging.Logger) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+	
+	log.Infof("Setting %d coordinate overrides", len(overrides))
+	
+	return transaction(db, func (tx *sql.Tx) error {
+		inserter := NewBulkInserter(6)
+		auditInserter := NewBulkInserter(8)
+		
+		for _, o := range overrides {
+			name := truncate(o.LocationName, 255)
+			note := truncate(o.Note, 255)
+			user := truncate(o.UpdatedBy, 255)
+			updatedAt := o.UpdatedAt.Unix()
+			inserter.Add(name, o.Coordinates.Lat, o.Coordinates.Lng, note, user, updatedAt)
+			auditInserter.Add(nil, name, types.OverrideSet, o.Coordinates.Lat, o.Coordinates.Lng, note, user, updatedAt)
+		}
+		
+		if _, err := inserter.ExecReplace(tx, "coordinate_overrides", nil); err != nil {
+			return err
+		}
+		if _, err := auditInserter.Exec(tx, "coordinate_override_audit", nil); err != nil {
+			return err
+		}
+		return touchDataUpdated(tx)
+	})
+}
+
+// Delete the coordinate override of a location name (if it exists) and record the change in the audit log. Returns
+// whether an override was deleted.
+func ClearCoordinateOverride(db *sql.DB, locName string, user string, now time.Time, log logging.Logger) (bool, error) {
+	log.Infof("Clearing coordinate override of location '%s'", locName)
+	
+	var cleared bool
+	err := transaction(db, func (tx *sql.Tx) error {
+		var o types.CoordinateOverride
+		row := tx.QueryRow("SELECT lat, lng, note FROM coordinate_overrides WHERE location_name = ?", locName)
+		err := row.Scan(&o.Coordinates.Lat, &o.Coordinates.Lng, &o.Note)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		
+		if _, err := tx.Exec("DELETE FROM coordinate_overrides WHERE location_name = ?", locName); err != nil {
+			return err
+		}
+		auditInserter := NewBulkInserter(8)
+		auditInserter.Add(nil, locName, types.OverrideClear, o.Coordinates.Lat, o.Coordinates.Lng, o.Note, truncate(user, 255), now.Unix())
+		if _, err = auditInserter.Exec(tx, "coordinate_override_audit", nil); err != nil {
+			return err
+		}
+		cleared = true
+		return touchDataUpdated(tx)
+	})
+	return cleared, err
+}
+
+// Store (or replace) poster thumbnails.
+func StorePosters(db *sql.DB, posters []types.Poster, log logging.Logger) error {
+	if len(posters) == 0 {
+		return nil
+	}
+	
+	log.Infof("Storing %d poster thumbnails", len(posters))
+	
+	return transaction(db, func (tx *sql.Tx) error {
+		inserter := NewBulkInserter(8)
+		for _, p := range posters {
+			k := p.Key
+			inserter.Add(k.Title, k.ReleaseYear, k.Director, p.Size, p.ContentType, p.Data, truncate(p.SourceUrl, 512), p.FetchedAt.Unix())
+		}
+		_, err := inserter.ExecReplace(tx, "posters", nil)
+		return err
+	})
+}
+
+// Pin (or re-pin) the IMDb ID of a movie.
+func StoreMovieMetadataPin(db *sql.DB, pin types.MovieMetadataPin, log logging.Logger) error {
+	log.Infof("Pinning IMDb ID %s to movie '%s'", pin.ImdbId, pin.Key.Title)
+	
+	return transaction(db, func (tx *sql.Tx) error {
+		inserter := NewBulkInserter(6)
+		k := pin.Key
+		inserter.Add(k.Title, k.ReleaseYear, k.Director, truncate(pin.ImdbId, 16), truncate(pin.UpdatedBy, 255), pin.UpdatedAt.Unix())
+		_, err := inserter.ExecReplace(tx, "movie_info_pins", nil)
+		return err
+	})
+}
+
+func DeleteMovieMetadataPin(db *sql.DB, key types.MovieKey, log logging.Logger) error {
+	log.Infof("Unpinning IMDb ID of movie '%s'", key.Title)
+	
+	return transaction(db, func (tx *sql.Tx) error {
+		condition, args := movieKeysCondition([]types.MovieKey{key})
+		_, err := tx.Exec("DELETE FROM movie_info_pins WHERE " + condition, args...)
+		return err
+	})
+}
+
+// Make the cached metadata of a movie expire such that it's refreshed by the next run of the refresher.
+func ExpireMovieMetadata(db *sql.DB, key types.MovieKey, log logging.Logger) error {
+	log.Infof("Expiring info of movie '%s'", key.Title)
+	
+	return transaction(db, func (tx *sql.Tx) error {
+		condition, args := movieKeysCondition([]types.MovieKey{key})
+		_, err := tx.Exec("UPDATE movie_info SET expires_at = 0 WHERE " + condition, args...)
+		return err
+	})
+}
+
+// Unix timestamp of a time (0 if it's unset).
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func truncate(str string, maxLen int) string {
+	rs := []rune(str)
+	if len(rs) > maxLen {
+		return string(rs[:maxLen])
+	}
+	return str
+}
+
+// Restart the background geocoding job from the first location name.
+func ResetGeocodeJob(db *sql.DB, log logging.Logger) error {
+	return transaction(db, func (tx *sql.Tx) error {
+		var total int
+		row := tx.QueryRow("SELECT COUNT(*) FROM (" + canonicalLocationNamesQuery + ") n", types.MergeMerged)
+		if err := row.Scan(&total); err != nil {
+			return err
+		}
+		
+		log.Infof("Resetting geocoding job for %d location names", total)
+		
+		now := time.Now().Unix()
+		_, err := tx.Exec("REPLACE INTO geocode_job VALUES (1, '', ?, 0, 0, 0, ?, ?, 0)", total, now, now)
+		return err
+	})
+}
+
+// Set the stamp with the given name to the given time.
+func SetStamp(db *sql.DB, name string, t time.Time, log logging.Logger) error {
+	log.Infof("Setting stamp '%s'", name)
+	
+	_, err := db.Exec("REPLACE INTO stamps (name, stamped_at) VALUES (?, ?)", name, t.UnixNano())
+	return err
+}
+
+// Name of the stamp of the last change of the movies, their locations, or the coordinates of these. Instances compare it
+// to the one their in-memory data (like the suggestion index) was built from to tell whether it's outdated.
+const DataUpdatedStamp = "data_updated"
+
+// Set the stamp of the last change of the data as part of the transaction that changes it.
+func touchDataUpdated(tx *sql.Tx) error {
+	_, err := tx.Exec("REPLACE INTO stamps (name, stamped_at) VALUES (?, ?)", DataUpdatedStamp, time.Now().UnixNano())
+	return err
+}
+
+func SaveGeocodeJob(db *sql.DB, job *types.GeocodeJob, log logging.Logger) error {
+	var finishedAt int64
+	if job.Finished() {
+		finishedAt = job.FinishedAt.Unix()
+	}
+	
+	return transaction(db, func (tx *sql.Tx) error {
+		_, err := tx.Exec(
+			"UPDATE geocode_job SET cursor_name = ?, processed = ?, resolved = ?, failed = ?, updated_at = ?, finished_at = ? WHERE id = 1",
+			job.Cursor,
+			job.Processed,
+			job.Resolved,
+			job.Failed,
+			job.UpdatedAt.Unix(),
+			finishedAt,
+		)
+		return err
+	})
+}
@@ -35,8 +35,43 @@ func transaction(db *sql.DB, callback func(*sql.Tx) error) error {
 	return tx.Commit()
 }
 
+func columnExists(tx *sql.Tx, tableName string, columnName string) (bool, error) {
+	row := tx.QueryRow(
+		"SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?",
+		tableName,
+		columnName,
+	)
+	
+	var count int
+	err := row.Scan(&count)
+	return count > 0, err
+}
+
+// Add columns (given as definitions like "name VARCHAR(255)") to a table unless they already exist. The columns are
+// appended in the given order. As the order of the columns thus depends on the migrations a database went through, values
+// are inserted into tables with added columns by name (see `NewColumnBulkInserter`).
+func addColumnsUnlessExist(tx *sql.Tx, tableName string, columnDefs []string, log logging.Logger) error {
+	for _, columnDef := range columnDefs {
+		columnName := strings.Fields(columnDef)[0]
+		exists, err := columnExists(tx, tableName, columnName)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		
+		log.Infof("Adding column '%s' to table '%s'", columnName, tableName)
+		if _, err := tx.Exec("ALTER TABLE " + tableName + " ADD COLUMN " + columnDef); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type BulkInsertStmtBuilder struct {
 	colCount int
+	columns  []string
 	rowCount int
 	values   []interface{}
 }
@@ -45,6 +80,11 @@ func NewBulkInserter(colCount int) BulkInsertStmtBuilder {
 	return BulkInsertStmtBuilder{colCount: colCount, rowCount: 0}
 }
 
+// Bulk inserter of values for the named columns (in the given order) rather than all columns of the table.
+func NewColumnBulkInserter(columns ...string) BulkInsertStmtBuilder {
+	return BulkInsertStmtBuilder{colCount: len(columns), columns: columns, rowCount: 0}
+}
+
 func (b *BulkInsertStmtBuilder) Add(values ...interface{}) *BulkInsertStmtBuilder {
 	if len(values) != b.colCount {
 		panic(fmt.Sprintf("Expected %d values but got %d", b.colCount, len(values)))
@@ -57,7 +97,7 @@ func (b *BulkInsertStmtBuilder) Add(values ...interface{}) *BulkInsertStmtBuilde
 	return b
 }
 
-func (b *BulkInsertStmtBuilder) build(tableName string) string {
+func (b *BulkInsertStmtBuilder) build(verb string, tableName string) string {
 	if (b.rowCount * b.colCount != len(b.values)) {
 		panic("Unexpected number of values...")
 	}
@@ -69,16 +109,34 @@ func (b *BulkInsertStmtBuilder) build(tableName string) string {
 	// Construct string with format "(?, ?, ..., ?)".
 	prpStmtStr := fancyRepeat("(", "?", b.colCount, ", ", ")")
 	
-	// Construct string with format "INSERT INTO table VALUES prpStmtStr, prpStmtStr, ..., prpStmtStr".
-	return fancyRepeat("INSERT INTO " + tableName + " VALUES", prpStmtStr, b.rowCount, ",", "")
+	// Construct string with format "INSERT INTO table (column, ...) VALUES prpStmtStr, prpStmtStr, ..., prpStmtStr".
+	into := verb + " INTO " + tableName
+	if len(b.columns) > 0 {
+		into += " (" + strings.Join(b.columns, ", ") + ")"
+	}
+	return fancyRepeat(into + " VALUES", prpStmtStr, b.rowCount, ",", "")
 }
 
 func (b *BulkInsertStmtBuilder) Exec(tx *sql.Tx, tableName string, log logging.Logger) (sql.Result, error) {
+	return b.exec(tx, "INSERT", tableName, log)
+}
+
+// Like `Exec`, but rows that would violate a unique key are skipped instead of failing the insertion.
+func (b *BulkInsertStmtBuilder) ExecIgnore(tx *sql.Tx, tableName string, log logging.Logger) (sql.Result, error) {
+	return b.exec(tx, "INSERT IGNORE", tableName, log)
+}
+
+// Like `Exec`, but rows that violate a unique key replace the existing ones.
+func (b *BulkInsertStmtBuilder) ExecReplace(tx *sql.Tx, tableName string, log logging.Logger) (sql.Result, error) {
+	return b.exec(tx, "REPLACE", tableName, log)
+}
+
+func (b *BulkInsertStmtBuilder) exec(tx *sql.Tx, verb string, tableName string, log logging.Logger) (sql.Result, error) {
 	if len(b.values) == 0 {
 		return nil, nil
 	}
 	
-	stmt := b.build(tableName)
+	stmt := b.build(verb, tableName)
 	if log != nil {
 		log.Debugf("Executing query '%s' with values %s", stmt, fmt.Sprintln(b.values))
 	}
@@ -1,5 +1,10 @@
 package types
 
+import (
+	"fmt"
+	"time"
+)
+
 type Movie struct {
 	Title             string
 	Locations         []Location
@@ -11,10 +16,158 @@ type Movie struct {
 	ReleaseYear       int
 }
 
+// Identity of a movie. Remakes and different productions with the same title are told apart by their release year and
+// director.
+type MovieKey struct {
+	Title       string
+	ReleaseYear int
+	Director    string
+}
+
+func (m *Movie) Key() MovieKey {
+	return MovieKey{Title: m.Title, ReleaseYear: m.ReleaseYear, Director: m.Director}
+}
+
+// Identity of the movie in log messages and task results (like "Vertigo (1958, Alfred Hitchcock)").
+func (k MovieKey) String() string {
+	return fmt.Sprintf("%s (%d, %s)", k.Title, k.ReleaseYear, k.Director)
+}
+
+// Metadata of a movie as normalized from the provider (like OMDB or TMDB) named by `Provider`. Fields that the provider
+// doesn't know are left empty.
+type MovieMetadata struct {
+	Provider       string
+	ProviderId     string
+	ImdbId         string
+	Title          string
+	Year           int
+	Released       string
+	RuntimeMinutes int
+	Rated          string
+	Genres         []string
+	Directors      []string
+	Writers        []string
+	Actors         []string
+	Plot           string
+	Languages      []string
+	Countries      []string
+	Awards         string
+	PosterUrl      string
+	Metascore      int
+	ImdbRating     float32
+	ImdbVotes      int
+	
+	// Confidence in [0, 1] that the provider's movie is the one of the data set (1 for pinned matches).
+	MatchConfidence float64
+}
+
+// Sources of the values of a movie.
+const (
+	SourceDataSf   = "datasf"
+	SourceMetadata = "metadata"
+)
+
+// Value of a field of a movie as reconciled between the data set and the metadata from the provider: `Values` are taken
+// from `Source`, which is the source with the highest priority that has any. `Discrepancy` describes how the sources
+// disagree (if they do). Discrepancies that are likely caused by wrong upstream data are `Suspicious`.
+type ReconciledField struct {
+	Field       string
+	Values      []string
+	Source      string
+	DataSf      []string
+	Metadata    []string
+	Discrepancy string
+	Suspicious  bool
+}
+
+type ReconciledCredits struct {
+	Year     ReconciledField
+	Director ReconciledField
+	Writers  ReconciledField
+	Actors   ReconciledField
+}
+
+func (c *ReconciledCredits) Fields() []ReconciledField {
+	return []ReconciledField{c.Year, c.Director, c.Writers, c.Actors}
+}
+
+// Field of a movie whose sources disagree.
+type CreditDiscrepancy struct {
+	MovieId  int64
+	Movie    MovieKey
+	Provider string
+	Field    ReconciledField
+}
+
+// Snapshot of the caches of movie info and coordinates (by location name) and of the pinned IMDb IDs that is exported
+// from one database and imported into another (see `data.ExportSeed`).
+type Seed struct {
+	MovieInfo   []CachedMovieMetadata
+	Coordinates map[string]Geocode
+	Pins        []MovieMetadataPin
+}
+
+// Thumbnail of the poster of a movie as cached from `SourceUrl` (the poster URL of the movie's metadata).
+type Poster struct {
+	Key         MovieKey
+	Size        string
+	ContentType string
+	Data        []byte
+	SourceUrl   string
+	FetchedAt   time.Time
+}
+
+// IMDb ID pinned by an admin as the match of a movie. It's fetched instead of searching the provider for the movie.
+type MovieMetadataPin struct {
+	Key       MovieKey
+	ImdbId    string
+	UpdatedBy string
+	UpdatedAt time.Time
+}
+
+// Roles of the credits of a movie.
+const (
+	CreditDirector = "director"
+	CreditWriter   = "writer"
+	CreditActor    = "actor"
+)
+
+// Cached metadata of a movie. `Metadata` is nil if the provider didn't find the movie. The entry is refreshed once it
+// has expired.
+type CachedMovieMetadata struct {
+	Key       MovieKey
+	Metadata  *MovieMetadata
+	Provider  string
+	FetchedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (c *CachedMovieMetadata) Expired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
+
 type Location struct {
 	Name        string
 	FunFact     string
+	Parts       LocationParts
 	Coordinates Coordinates
+	Geocode     Geocode `json:"-"`
+}
+
+// Structured parts of a location name. Any of the parts may be empty.
+type LocationParts struct {
+	Landmark     string
+	Address      string
+	Intersection [2]string
+	Neighborhood string
+	Range        StreetRange
+}
+
+// Stretch of a street between two cross streets as in "Market St from 1st to 5th".
+type StreetRange struct {
+	Street string
+	From   string
+	To     string
 }
 
 type Coordinates struct {
@@ -22,9 +175,156 @@ type Coordinates struct {
 	Lng float32
 }
 
+// Rectangle given by its southern and northern latitude and western and eastern longitude.
+type Bounds struct {
+	South float32
+	West  float32
+	North float32
+	East  float32
+}
+
+func (b Bounds) Contains(c Coordinates) bool {
+	return b.South <= c.Lat && c.Lat <= b.North && b.West <= c.Lng && c.Lng <= b.East
+}
+
+// Precision of a geocode (as reported by the Google Geocoding API).
+const (
+	LocationRooftop           = "ROOFTOP"
+	LocationRangeInterpolated = "RANGE_INTERPOLATED"
+	LocationGeometricCenter   = "GEOMETRIC_CENTER"
+	LocationApproximate       = "APPROXIMATE"
+)
+
+// Coordinates of a location name along with the name of the geocoder that found them (`Source`), the details of the
+// match, and the query that matched. Further results of the query are given as `Alternatives` (these are not cached).
+type Geocode struct {
+	Coordinates      Coordinates
+	Source           string
+	FormattedAddress string
+	LocationType     string
+	PlaceTypes       []string
+	Viewport         Bounds
+	Query            string
+	Alternatives     []Geocode `json:",omitempty"`
+}
+
+// Whether the coordinates only approximate the location (e.g. the center of a neighborhood or street).
+func (g Geocode) LowConfidence() bool {
+	return g.LocationType == LocationApproximate || g.LocationType == LocationGeometricCenter
+}
+
+// Source of coordinates that were set manually by an admin.
+const OverrideSource = "override"
+
+// Coordinates of a location name set manually by an admin. Overrides take precedence over cached geocodes.
+type CoordinateOverride struct {
+	LocationName string
+	Coordinates  Coordinates
+	Note         string
+	UpdatedBy    string
+	UpdatedAt    time.Time
+}
+
+const (
+	ReviewPending  = "pending"
+	ReviewResolved = "resolved"
+	ReviewRejected = "rejected"
+)
+
+// Ambiguous geocode of a location name queued for review by an admin. The first candidate is the one that was cached.
+type GeocodeReview struct {
+	LocationName string
+	Reason       string
+	Candidates   []Geocode
+	Status       string
+	CreatedAt    time.Time
+	ResolvedBy   string
+	ResolvedAt   time.Time
+}
+
+// Whether the given coordinates are those of one of the candidates of the review.
+func (r GeocodeReview) HasCandidate(c Coordinates) bool {
+	for _, g := range r.Candidates {
+		if g.Coordinates == c {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	OverrideSet   = "set"
+	OverrideClear = "clear"
+)
+
+// Entry of the audit log of changes to coordinate overrides. The coordinates and note are the ones that were set (or
+// cleared).
+type CoordinateOverrideChange struct {
+	LocationName string
+	Action       string
+	Coordinates  Coordinates
+	Note         string
+	User         string
+	ChangedAt    time.Time
+}
+
+const (
+	MergeMerged   = "merged"
+	MergePending  = "pending"
+	MergeRejected = "rejected"
+)
+
+// Proposal to treat a variant of a location name as an alias of a canonical name.
+type LocationMerge struct {
+	Alias     string
+	Canonical string
+	Score     float64
+	Status    string
+}
+
+// Failed attempt to geocode a location name. The name is not geocoded again until `RetryAt`.
+type GeocodeFailure struct {
+	LocationName string
+	Reason       string
+	Attempts     int
+	FailedAt     time.Time
+	RetryAt      time.Time
+}
+
+func (f *GeocodeFailure) Expired(now time.Time) bool {
+	return !now.Before(f.RetryAt)
+}
+
+// Checkpointed progress of the background job that geocodes all (canonical) location names in order.
+type GeocodeJob struct {
+	Cursor     string
+	Total      int
+	Processed  int
+	Resolved   int
+	Failed     int
+	StartedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt time.Time
+}
+
+func (j *GeocodeJob) Finished() bool {
+	return !j.FinishedAt.IsZero()
+}
+
 type IdMoviePair struct {
-	Id    int64
-	Movie Movie
+	Id       int64
+	Movie    Movie
+	Metadata *MovieMetadata `json:",omitempty"`
+}
+
+// Criteria for listing movies by their metadata. Zero values match any movie; other criteria only match movies with
+// metadata.
+type MovieFilter struct {
+	Genre             string
+	Language          string
+	Country           string
+	MinImdbRating     float32
+	MaxRuntimeMinutes int
 }
 
 // Comparator for sorting movie list.
@@ -37,5 +337,10 @@ func (ms ByTitle) Swap(i, j int) {
 	ms[i], ms[j] = ms[j], ms[i]
 }
 func (ms ByTitle) Less(i, j int) bool {
-	return ms[i].Movie.Title < ms[j].Movie.Title
+	mi := ms[i].Movie
+	mj := ms[j].Movie
+	if mi.Title != mj.Title {
+		return mi.Title < mj.Title
+	}
+	return mi.ReleaseYear < mj.ReleaseYear
 }
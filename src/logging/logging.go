@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 	"log"
+	"sync"
 )
 
 // TODO Add facade with functions for setting logging output level.
@@ -49,10 +50,13 @@ func (logger *InitLogger) Criticalf(format string, args ...interface{}) {
 	log.Printf("CRITICAL(init): " + format + "\n", args...)
 }
 
+// Logger that records the entries written to it in addition to passing them on to a wrapped logger. It may be used
+// from multiple goroutines.
 type RecordingLogger struct {
 	wrapped Logger
 	init    bool
-	Entries []string
+	mutex   sync.Mutex
+	entries []string
 }
 
 func NewRecordingLogger(wrapped Logger, init bool) *RecordingLogger {
@@ -69,10 +73,22 @@ func (l *RecordingLogger) add(kind string, format string, args ...interface{}) s
 	}
 	fullMsg := fmt.Sprintf("(%s): %s%s", ts, initStr, msg)
 	
-	l.Entries = append(l.Entries, kind + fullMsg)
+	l.mutex.Lock()
+	l.entries = append(l.entries, kind + fullMsg)
+	l.mutex.Unlock()
 	return fullMsg
 }
 
+// Copy of the entries recorded so far.
+func (l *RecordingLogger) Entries() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	
+	entries := make([]string, len(l.entries))
+	copy(entries, l.entries)
+	return entries
+}
+
 func (l *RecordingLogger) Debugf(format string, args ...interface{}) {
 	msg := l.add("DEBUG", format, args...)
 	if l.wrapped != nil {
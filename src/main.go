@@ -1,15 +1,18 @@
 package app
 
 import (
+	"src/api"
 	"src/data"
 	"src/data/types"
 	"src/data/sqldb"
 	"src/data/fetch"
+	"src/data/pipeline"
 	"src/config"
 	"src/tpl"
 	"src/logging"
 	"src/watch"
 	"appengine"
+	"appengine/user"
 	"net/http"
 	"database/sql"
 	"encoding/json"
@@ -17,6 +20,8 @@ import (
 	"strconv"
 	"fmt"
 	"errors"
+	"sync"
+	"time"
 	_ "github.com/go-sql-driver/mysql"
 )
 
@@ -24,17 +29,27 @@ var db *sql.DB
 
 var recordedLog []string
 var recordedError error
+var recordedReport *fetch.ValidationReport
 
 var jsonFileName = config.JsonFileName()
-var mapsApiKey = config.MapsApiKey()
+var seedFileName = config.SeedFileName()
+var geocoders = fetch.NewGeocoders()
+var metadataProvider = fetch.NewMetadataProvider()
+
+// Catalog of the API (without filters) and index of the suggestions for autocompletion along with the data stamp they
+// were built at. They're rebuilt on demand once the data has changed since (on any instance).
+var catalog *api.Catalog
+var suggestIndex *api.SuggestIndex
+var catalogStamp time.Time
+var catalogMutex = &sync.Mutex{}
 
 func init() {
 	log := logging.NewRecordingLogger(&logging.InitLogger{}, true)
 	
 	log.Infof("Spinning up instance with ID '%s'", appengine.InstanceID())
 	
-	err := openInit(log)
-	recordInitUpdate(err, log)
+	report, err := openInit(log)
+	recordInitUpdate(err, report, log)
 	if err != nil {
 		panic(err)
 	}
@@ -43,30 +58,41 @@ func init() {
 	http.HandleFunc("/movie", render(movies))
 	http.HandleFunc("/movie/", render(movie))
 	http.HandleFunc("/status", renderStatus)
+	http.HandleFunc("/status/report.json", renderReportJson)
 	http.HandleFunc("/update", renderUpdate)
 	http.HandleFunc("/ping", renderPing)
+	http.HandleFunc("/admin/merges", render(merges))
+	http.HandleFunc("/admin/geocode-failures", render(geocodeFailures))
+	http.HandleFunc("/admin/coordinates", render(coordinateOverrides))
+	http.HandleFunc("/admin/geocode-reviews", render(geocodeReviews))
+	http.HandleFunc("/admin/movie-pins", render(moviePins))
+	http.HandleFunc("/admin/seed", render(seed))
+	http.HandleFunc("/admin/credit-discrepancies", render(creditDiscrepancies))
+	http.HandleFunc("/tasks/geocode", renderGeocodeTask)
+	http.HandleFunc("/tasks/refresh-movie-info", renderRefreshMovieInfoTask)
 	http.HandleFunc("/data", renderDataJson)
+	http.HandleFunc("/poster/", renderPoster)
+	http.HandleFunc(api.BasePath + "/", renderApi)
+	http.HandleFunc(api.SuggestPath, renderSuggest)
 	
 	// TODO Make "raw data dump" page.
 	// TODO Add pages for actor, ...
 }
 
-func openInit(log *logging.RecordingLogger) error {
+func openInit(log *logging.RecordingLogger) (*fetch.ValidationReport, error) {
 	if err := openDb(log); err != nil {
-		return err
+		return nil, err
 	}
-	if _, err := data.Init(db, jsonFileName, log); err != nil {
-		return err
-	}
-	return nil
+	_, report, err := data.Init(db, jsonFileName, seedFileName, log)
+	return report, err
 }
 
-func recordInitUpdate(err error, log *logging.RecordingLogger) {
+func recordInitUpdate(err error, report *fetch.ValidationReport, log *logging.RecordingLogger) {
 	recordedError = err
-	entries := log.Entries
-	entriesCopy := make([]string, len(entries))
-	copy(entriesCopy, entries)
-	recordedLog = entriesCopy
+	if report != nil {
+		recordedReport = report
+	}
+	recordedLog = log.Entries()
 }
 
 func openDb(logger logging.Logger) error {
@@ -81,17 +107,22 @@ func openDb(logger logging.Logger) error {
 	return err
 }
 
+// Check if the database is initialized and load it from file if it isn't. Tables of an initialized database are migrated
+// by the first call on each instance.
+func initDb(log *logging.RecordingLogger) error {
+	initialized, report, err := data.Init(db, jsonFileName, seedFileName, log)
+	if initialized {
+		recordInitUpdate(err, report, log)
+	}
+	return err
+}
+
 func render(renderer func(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger) error) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := appengine.NewContext(r)
 		log := logging.NewRecordingLogger(ctx, false)
 		
-		// Check if database is initialized and load from file if it isn't.
-		initialized, err := data.Init(db, jsonFileName, log)
-		if initialized {
-			recordInitUpdate(err, log)
-		}
-		
+		err := initDb(log)
 		if err == nil {
 			err = renderer(w, r, log)
 		}
@@ -124,7 +155,17 @@ func movie(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger)
 	
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Invalid movie ID '%s'", idStr))
+		// Movies may also be identified by their ID in the API (which stays the same across updates).
+		c, _, err := currentCatalog(log)
+		if err != nil {
+			return err
+		}
+		dbId, exists := c.MovieDbId(idStr)
+		if !exists {
+			http.Error(w, fmt.Sprintf("Movie '%s' not found", idStr), http.StatusNotFound)
+			return nil
+		}
+		id = int(dbId)
 	}
 	
 	log.Infof("Rendering movie with ID %d", id)
@@ -135,81 +176,92 @@ func movie(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger)
 		return nil
 	}
 	
+	// Coordinates are cached under the canonical names of merged location name variants.
+	var locNames []string
+	for _, loc := range movie.Locations {
+		locNames = append(locNames, loc.Name)
+	}
+	aliases, err := sqldb.LoadLocationAliases(db, locNames, log)
+	if err != nil {
+		return err
+	}
+	canonicalName := func(locName string) string {
+		if canonical, exists := aliases[locName]; exists {
+			return canonical
+		}
+		return locName
+	}
+	canonicalLocs := make([]types.Location, len(movie.Locations))
+	for i, loc := range movie.Locations {
+		canonicalLocs[i] = loc
+		canonicalLocs[i].Name = canonicalName(loc.Name)
+	}
+	
 	log.Infof("Loading coordinates")
-	locNameCoordsMap, err := sqldb.LoadCoordinates(db, movie.Locations, log)
+	locNameCoordsMap, err := sqldb.LoadCoordinates(db, canonicalLocs, log)
+	if err != nil {
+		return err
+	}
 	
-	missingCoords := make(map[string]*types.Coordinates)
-	for _, loc := range movie.Locations {
-		locName := loc.Name
-		if _, exists := locNameCoordsMap[locName]; !exists {
-			missingCoords[locName] = nil
+	locNamePartsMap := make(map[string]types.LocationParts)
+	for _, loc := range canonicalLocs {
+		if _, exists := locNameCoordsMap[loc.Name]; !exists {
+			locNamePartsMap[loc.Name] = loc.Parts
 		}
 	}
 	
 	// Load missing coordinates.
 	ctx := appengine.NewContext(r)
-	delayFunc := func (count int) int { return 50 * count }
-	fetch.FetchMissingLocationNames(missingCoords, delayFunc, mapsApiKey, ctx, log)
-	
-	// Store missing coordinates.
-	if err := sqldb.StoreCoordinates(db, missingCoords, log); err != nil {
+	fetchedCoords, _, err := data.GeocodeLocations(db, locNamePartsMap, geocoders, ctx, log)
+	if err != nil {
 		return err
 	}
-	
-	// Add missing coordinates to `coords`.
-	for locName, locCoords := range missingCoords {
-		if locCoords != nil {
-			locNameCoordsMap[locName] = *locCoords
-		}
+	for locName, locCoords := range fetchedCoords {
+		locNameCoordsMap[locName] = locCoords
 	}
 	
 	// Set coordinates on locations.
 	for i := range movie.Locations {
 		loc := &movie.Locations[i]
-		loc.Coordinates = locNameCoordsMap[loc.Name]
-	}
-	
-	type MovieInfo struct {
-		Title      string
-		Year       string
-		Rated      string
-		Released   string
-		Runtime    string
-		Genre      string
-		Director   string
-		Writer     string
-		Actors     string
-		Plot       string
-		Language   string
-		Country    string
-		Awards     string
-		Poster     string
-		Metascore  string
-		ImdbRating string
-		ImdbVotes  string
-		ImdbID     string
-	}
-	
-	var info MovieInfo
-	
-	info.Title = movie.Title
-	info.Actors = strings.Join(movie.Actors, ", ")
-	info.Writer = movie.Writer
-	info.Director = movie.Director
-	info.Released = strconv.Itoa(movie.ReleaseYear)
+		loc.Geocode = locNameCoordsMap[canonicalName(loc.Name)]
+		loc.Coordinates = loc.Geocode.Coordinates
+	}
 	
-	args := &struct {
-		Movie    *types.Movie
-		Info     *MovieInfo
-	}{&movie, &info}
-	
-	if infoJson, err := sqldb.LoadMovieInfoJson(db, movie.Title, log); infoJson != "" && err == nil {
-		// Only attempt to parse JSON if it was loaded successfully
-		if err := json.Unmarshal([]byte(infoJson), &info); err != nil {
-			log.Errorf(err.Error())
-		}
+	cached, err := sqldb.LoadMovieMetadata(db, movie.Key(), log)
+	if err != nil {
+		log.Errorf("%s", err)
+	}
+	var metadata *types.MovieMetadata
+	if cached != nil {
+		metadata = cached.Metadata
+	}
+	info := metadata
+	if info == nil {
+		// Fall back to the data of the data set (whose credits and year are included by the reconciliation).
+		info = &types.MovieMetadata{Title: movie.Title}
+	}
+	
+	pins, err := sqldb.LoadMovieMetadataPins(db, log)
+	if err != nil {
+		log.Errorf("%s", err)
+	}
+	pinned := false
+	for _, p := range pins {
+		pinned = pinned || p.Key == movie.Key()
 	}
 	
+	args := &struct {
+		Movie         *types.Movie
+		MovieId       int
+		ApiMovieId    string
+		Info          *types.MovieMetadata
+		PosterVersion string
+		Credits       types.ReconciledCredits
+		Cached        *types.CachedMovieMetadata
+		Pinned        bool
+		Now           time.Time
+	}{&movie, id, api.MovieId(movie.Key()), info, data.PosterVersion(movie.Key(), info.PosterUrl), fetch.ReconcileCredits(movie, metadata), cached, pinned, time.Now()}
+	
 	templateData := tpl.NewTemplateData(ctx, log, args)
 	templateData.Subtitle = info.Title
 	return tpl.Render(w, tpl.Movie, templateData)
@@ -220,13 +272,19 @@ func movies(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger
 	
 	log.Infof("Rendering movie list page")
 	
-	movies, err := sqldb.LoadMovies(db, log)
+	filter := parseMovieFilter(r)
+	movies, err := sqldb.LoadMovies(db, filter, log)
 	if err != nil {
 		return err
 	}
 	
+	args := &struct {
+		Filter types.MovieFilter
+		Movies []types.IdMoviePair
+	}{filter, movies}
+	
 	ctx := appengine.NewContext(r)
-	templateData := tpl.NewTemplateData(ctx, log, movies)
+	templateData := tpl.NewTemplateData(ctx, log, args)
 	templateData.Subtitle = "List"
 	if err := tpl.Render(w, tpl.Movies, templateData); err != nil {
 		return err
@@ -235,14 +293,539 @@ func movies(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger
 	return nil
 }
 
-// TODO Have one optimized endpoint with only data needed for autocomplete and one with *all* data.
+// Movie filter from the query parameters "genre", "language", "country", "min_rating", and "max_runtime" (in minutes).
+// Invalid numbers are ignored.
+func parseMovieFilter(r *http.Request) types.MovieFilter {
+	filter := types.MovieFilter{
+		Genre:    strings.TrimSpace(r.FormValue("genre")),
+		Language: strings.TrimSpace(r.FormValue("language")),
+		Country:  strings.TrimSpace(r.FormValue("country")),
+	}
+	if rating, err := strconv.ParseFloat(r.FormValue("min_rating"), 32); err == nil {
+		filter.MinImdbRating = float32(rating)
+	}
+	if runtime, err := strconv.Atoi(r.FormValue("max_runtime")); err == nil {
+		filter.MaxRuntimeMinutes = runtime
+	}
+	return filter
+}
+
+func merges(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger) error {
+	preventCaching(w);
+	
+	if r.Method == "POST" {
+		alias := r.FormValue("alias")
+		var status string
+		switch r.FormValue("action") {
+		case "accept":
+			status = types.MergeMerged
+		case "reject":
+			status = types.MergeRejected
+		default:
+			http.Error(w, fmt.Sprintf("Invalid action '%s'", r.FormValue("action")), http.StatusBadRequest)
+			return nil
+		}
+		
+		if err := sqldb.SetLocationMergeStatus(db, alias, status, log); err != nil {
+			return err
+		}
+		http.Redirect(w, r, "/admin/merges", http.StatusFound)
+		return nil
+	}
+	
+	log.Infof("Rendering location name merge review page")
+	
+	pending, err := sqldb.LoadLocationMerges(db, types.MergePending, log)
+	if err != nil {
+		return err
+	}
+	merged, err := sqldb.LoadLocationMerges(db, types.MergeMerged, log)
+	if err != nil {
+		return err
+	}
+	
+	args := &struct {
+		Pending []types.LocationMerge
+		Merged  []types.LocationMerge
+	}{pending, merged}
+	
+	ctx := appengine.NewContext(r)
+	templateData := tpl.NewTemplateData(ctx, log, args)
+	templateData.Subtitle = "Location name merges"
+	return tpl.Render(w, tpl.Merges, templateData)
+}
+
+func geocodeFailures(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger) error {
+	preventCaching(w);
+	
+	if r.Method == "POST" {
+		var locNames []string
+		switch r.FormValue("action") {
+		case "clear":
+			if err := r.ParseForm(); err != nil {
+				return err
+			}
+			// Clear nothing rather than everything if nothing was selected.
+			locNames = append([]string{}, r.PostForm["name"]...)
+		case "clear-all":
+			locNames = nil
+		default:
+			http.Error(w, fmt.Sprintf("Invalid action '%s'", r.FormValue("action")), http.StatusBadRequest)
+			return nil
+		}
+		
+		count, err := sqldb.ClearGeocodeFailures(db, locNames, log)
+		if err != nil {
+			return err
+		}
+		log.Infof("Cleared %d geocode failures", count)
+		http.Redirect(w, r, "/admin/geocode-failures", http.StatusFound)
+		return nil
+	}
+	
+	log.Infof("Rendering geocode failure page")
+	
+	failures, err := sqldb.LoadAllGeocodeFailures(db, log)
+	if err != nil {
+		return err
+	}
+	
+	args := &struct {
+		Failures []types.GeocodeFailure
+		Now      time.Time
+	}{failures, time.Now()}
+	
+	ctx := appengine.NewContext(r)
+	templateData := tpl.NewTemplateData(ctx, log, args)
+	templateData.Subtitle = "Geocode failures"
+	return tpl.Render(w, tpl.GeocodeFailures, templateData)
+}
+
+func currentUserName(ctx appengine.Context) string {
+	if u := user.Current(ctx); u != nil {
+		return u.String()
+	}
+	return "unknown"
+}
+
+func geocodeReviews(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger) error {
+	preventCaching(w);
+	
+	ctx := appengine.NewContext(r)
+	
+	if r.Method == "POST" {
+		review, err := sqldb.LoadGeocodeReview(db, r.FormValue("name"), log)
+		if err != nil {
+			return err
+		}
+		if review == nil {
+			http.Error(w, fmt.Sprintf("No review of location '%s'", r.FormValue("name")), http.StatusNotFound)
+			return nil
+		}
+		
+		userName := currentUserName(ctx)
+		switch r.FormValue("action") {
+		case "pick":
+			candidate, err := strconv.Atoi(r.FormValue("candidate"))
+			if err != nil || candidate < 0 || candidate >= len(review.Candidates) {
+				http.Error(w, fmt.Sprintf("Invalid candidate '%s'", r.FormValue("candidate")), http.StatusBadRequest)
+				return nil
+			}
+			err = data.AcceptGeocodeReview(db, review, candidate, types.Coordinates{}, userName, log)
+			if err != nil {
+				return err
+			}
+		case "correct":
+			lat, latErr := strconv.ParseFloat(r.FormValue("lat"), 32)
+			lng, lngErr := strconv.ParseFloat(r.FormValue("lng"), 32)
+			if latErr != nil || lngErr != nil {
+				http.Error(w, "Latitude and longitude are required", http.StatusBadRequest)
+				return nil
+			}
+			coords := types.Coordinates{Lat: float32(lat), Lng: float32(lng)}
+			if err := data.CheckOverrideCoordinates(coords); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
+			if err := data.AcceptGeocodeReview(db, review, -1, coords, userName, log); err != nil {
+				return err
+			}
+		case "reject":
+			if err := data.RejectGeocodeReview(db, review, userName, log); err != nil {
+				return err
+			}
+		default:
+			http.Error(w, fmt.Sprintf("Invalid action '%s'", r.FormValue("action")), http.StatusBadRequest)
+			return nil
+		}
+		http.Redirect(w, r, "/admin/geocode-reviews", http.StatusFound)
+		return nil
+	}
+	
+	log.Infof("Rendering geocode review page")
+	
+	pending, err := sqldb.LoadGeocodeReviews(db, types.ReviewPending, 20, log)
+	if err != nil {
+		return err
+	}
+	
+	args := &struct {
+		Pending []types.GeocodeReview
+	}{pending}
+	
+	templateData := tpl.NewTemplateData(ctx, log, args)
+	templateData.Subtitle = "Geocode reviews"
+	return tpl.Render(w, tpl.GeocodeReviews, templateData)
+}
+
+func coordinateOverrides(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger) error {
+	preventCaching(w);
+	
+	ctx := appengine.NewContext(r)
+	
+	if r.Method == "POST" {
+		userName := currentUserName(ctx)
+		now := time.Now()
+		
+		switch r.FormValue("action") {
+		case "set":
+			lat, latErr := strconv.ParseFloat(r.FormValue("lat"), 32)
+			lng, lngErr := strconv.ParseFloat(r.FormValue("lng"), 32)
+			name := strings.TrimSpace(r.FormValue("name"))
+			if latErr != nil || lngErr != nil || name == "" {
+				http.Error(w, "Location name, latitude, and longitude are required", http.StatusBadRequest)
+				return nil
+			}
+			coords := types.Coordinates{Lat: float32(lat), Lng: float32(lng)}
+			if err := data.CheckOverrideCoordinates(coords); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
+			override := types.CoordinateOverride{
+				LocationName: name,
+				Coordinates:  coords,
+				Note:         r.FormValue("note"),
+				UpdatedBy:    userName,
+				UpdatedAt:    now,
+			}
+			if err := data.SetCoordinateOverrides(db, []types.CoordinateOverride{override}, log); err != nil {
+				return err
+			}
+		case "clear":
+			if _, err := sqldb.ClearCoordinateOverride(db, r.FormValue("name"), userName, now, log); err != nil {
+				return err
+			}
+		case "import":
+			file, _, err := r.FormFile("csv")
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Missing CSV file: %s", err), http.StatusBadRequest)
+				return nil
+			}
+			defer file.Close()
+			
+			overrides, err := data.ParseCoordinateOverridesCsv(file, userName, now)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid CSV file: %s", err), http.StatusBadRequest)
+				return nil
+			}
+			if err := data.SetCoordinateOverrides(db, overrides, log); err != nil {
+				return err
+			}
+			log.Infof("Imported %d coordinate overrides", len(overrides))
+		default:
+			http.Error(w, fmt.Sprintf("Invalid action '%s'", r.FormValue("action")), http.StatusBadRequest)
+			return nil
+		}
+		http.Redirect(w, r, "/admin/coordinates", http.StatusFound)
+		return nil
+	}
+	
+	log.Infof("Rendering coordinate override page")
+	
+	overrides, err := sqldb.LoadCoordinateOverrides(db, log)
+	if err != nil {
+		return err
+	}
+	changes, err := sqldb.LoadCoordinateOverrideAudit(db, 100, log)
+	if err != nil {
+		return err
+	}
+	
+	args := &struct {
+		Overrides []types.CoordinateOverride
+		Changes   []types.CoordinateOverrideChange
+	}{overrides, changes}
+	
+	templateData := tpl.NewTemplateData(ctx, log, args)
+	templateData.Subtitle = "Coordinate overrides"
+	return tpl.Render(w, tpl.CoordinateOverrides, templateData)
+}
+
+func moviePins(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger) error {
+	preventCaching(w);
+	
+	ctx := appengine.NewContext(r)
+	
+	if r.Method == "POST" {
+		var movie types.Movie
+		imdbId := strings.TrimSpace(r.FormValue("imdb_id"))
+		switch r.FormValue("action") {
+		case "pin":
+			id, err := strconv.Atoi(r.FormValue("movie"))
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid movie ID '%s'", r.FormValue("movie")), http.StatusBadRequest)
+				return nil
+			}
+			if movie, err = sqldb.LoadMovie(db, int64(id), log); err != nil {
+				http.Error(w, fmt.Sprintf("Movie with ID %d not found", id), http.StatusNotFound)
+				return nil
+			}
+			if imdbId == "" {
+				http.Error(w, "IMDb ID is required", http.StatusBadRequest)
+				return nil
+			}
+			if !data.IsImdbId(imdbId) {
+				http.Error(w, fmt.Sprintf("Invalid IMDb ID '%s'", imdbId), http.StatusBadRequest)
+				return nil
+			}
+		case "unpin":
+			year, _ := strconv.Atoi(r.FormValue("release_year"))
+			movie = types.Movie{Title: r.FormValue("title"), ReleaseYear: year, Director: r.FormValue("director")}
+			imdbId = ""
+		default:
+			http.Error(w, fmt.Sprintf("Invalid action '%s'", r.FormValue("action")), http.StatusBadRequest)
+			return nil
+		}
+		
+		if err := data.PinMovieMetadata(db, metadataProvider, movie, imdbId, currentUserName(ctx), ctx, log); err != nil {
+			return err
+		}
+		http.Redirect(w, r, "/admin/movie-pins", http.StatusFound)
+		return nil
+	}
+	
+	log.Infof("Rendering movie pin page")
+	
+	pins, err := sqldb.LoadMovieMetadataPins(db, log)
+	if err != nil {
+		return err
+	}
+	
+	args := &struct {
+		Pins    []types.MovieMetadataPin
+		MovieId string
+	}{pins, r.FormValue("movie")}
+	
+	templateData := tpl.NewTemplateData(ctx, log, args)
+	templateData.Subtitle = "Movie pins"
+	return tpl.Render(w, tpl.MoviePins, templateData)
+}
+
+func creditDiscrepancies(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger) error {
+	preventCaching(w);
+	
+	log.Infof("Rendering credit discrepancy report")
+	
+	movies, err := sqldb.LoadMovies(db, types.MovieFilter{}, log)
+	if err != nil {
+		return err
+	}
+	
+	ctx := appengine.NewContext(r)
+	templateData := tpl.NewTemplateData(ctx, log, fetch.CreditDiscrepancies(movies))
+	templateData.Subtitle = "Credit discrepancies"
+	return tpl.Render(w, tpl.CreditDiscrepancies, templateData)
+}
+
+// Export the seed of the movie info and coordinates caches as a JSON file (to be committed as `res/data/seed.json`) or
+// import an uploaded one.
+func seed(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger) error {
+	preventCaching(w);
+	
+	if r.Method == "POST" {
+		file, _, err := r.FormFile("seed")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Missing seed file: %s", err), http.StatusBadRequest)
+			return nil
+		}
+		defer file.Close()
+		
+		s, err := data.ReadSeed(file)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid seed file: %s", err), http.StatusBadRequest)
+			return nil
+		}
+		movieInfoCount, coordinatesCount, err := data.ImportSeed(db, s, log)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Imported %d movie infos and %d coordinates (cached entries were kept)\n", movieInfoCount, coordinatesCount)
+		return nil
+	}
+	
+	s, err := data.ExportSeed(db, log)
+	if err != nil {
+		return err
+	}
+	bytes, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=seed.json")
+	_, err = w.Write(bytes)
+	return err
+}
+
+// Serve the versioned API (see package `api`). The movie filter parameters of the movie list apply to all of its lists.
+// Unfiltered requests are served from the cached catalog.
+func renderApi(w http.ResponseWriter, r *http.Request) {
+	preventCaching(w);
+	
+	ctx := appengine.NewContext(r)
+	
+	if err := initDb(logging.NewRecordingLogger(ctx, false)); err != nil {
+		ctx.Errorf("ERROR: %+v", err)
+		api.WriteError(w, http.StatusInternalServerError, "internal_error", "The request could not be served")
+		return
+	}
+	
+	var c *api.Catalog
+	var err error
+	filter := parseMovieFilter(r)
+	if filter == (types.MovieFilter{}) {
+		c, _, err = currentCatalog(ctx)
+	} else {
+		var movies []types.IdMoviePair
+		var aliases map[string]string
+		movies, aliases, err = data.LoadMoviesWithCoordinates(db, filter, ctx)
+		if err == nil {
+			c = api.NewCatalog(movies, aliases)
+		}
+	}
+	if err != nil {
+		// The error may reveal internals (like queries), so it's only logged.
+		ctx.Errorf("ERROR: %+v", err)
+		api.WriteError(w, http.StatusInternalServerError, "internal_error", "The request could not be served")
+		return
+	}
+	api.Serve(w, r, c)
+}
+
+// Serve the poster thumbnail of the movie with the ID in the path in the size given by the parameter "size" (default
+// "medium") from the poster cache. Browsers may only cache it for long if the parameter "v" is the version of the
+// poster.
+func renderPoster(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	
+	if err := initDb(logging.NewRecordingLogger(ctx, false)); err != nil {
+		ctx.Errorf("ERROR: %+v", err)
+		http.Error(w, "Poster could not be loaded", http.StatusInternalServerError)
+		return
+	}
+	
+	idStr := r.URL.Path[strings.LastIndex(r.URL.Path, "/") + 1:]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid movie ID '%s'", idStr), http.StatusBadRequest)
+		return
+	}
+	size := r.FormValue("size")
+	if size == "" {
+		size = "medium"
+	}
+	widths, maxAgeDays, _ := config.PosterSettings()
+	if _, exists := widths[size]; !exists {
+		http.Error(w, fmt.Sprintf("Invalid poster size '%s'", size), http.StatusBadRequest)
+		return
+	}
+	
+	movie, err := sqldb.LoadMovie(db, int64(id), ctx)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Movie with ID %d not found", id), http.StatusNotFound)
+		return
+	}
+	poster, err := data.LoadPoster(db, movie, size, ctx, ctx)
+	if err != nil {
+		ctx.Errorf("Poster of movie %d could not be loaded: %s", id, err.Error())
+		http.Error(w, "Poster could not be loaded", http.StatusBadGateway)
+		return
+	}
+	if poster == nil {
+		http.Error(w, fmt.Sprintf("Movie with ID %d has no poster", id), http.StatusNotFound)
+		return
+	}
+	
+	// Only URLs with the version of the served poster may be cached for long as the movie IDs change with each update.
+	version := data.PosterVersion(movie.Key(), poster.SourceUrl)
+	maxAge := 0
+	if r.FormValue("v") == version {
+		maxAge = maxAgeDays * 24 * 60 * 60
+	}
+	etag := fmt.Sprintf("\"%s-%s\"", version, size)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", poster.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(poster.Data)))
+	w.Write(poster.Data)
+}
+
+// Get the catalog and the suggestion index, which are first rebuilt from the movies and location aliases in the
+// database if the data has changed since they were built (by this or any other instance, e.g. through an update, the
+// background geocoder, a merge, or an override).
+func currentCatalog(log logging.Logger) (*api.Catalog, *api.SuggestIndex, error) {
+	// The stamp is loaded before the data, so changes made in between only cause another (unnecessary) rebuild.
+	stamp, err := sqldb.LoadStamp(db, sqldb.DataUpdatedStamp)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	catalogMutex.Lock()
+	defer catalogMutex.Unlock()
+	if catalog != nil && stamp.Equal(catalogStamp) {
+		return catalog, suggestIndex, nil
+	}
+	
+	log.Infof("Rebuilding catalog of data updated at %s", stamp)
+	movies, aliases, err := data.LoadMoviesWithCoordinates(db, types.MovieFilter{}, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	catalog = api.NewCatalog(movies, aliases)
+	suggestIndex = api.NewSuggestIndex(catalog)
+	catalogStamp = stamp
+	return catalog, suggestIndex, nil
+}
+
+// Serve suggestions for autocompletion (see `api.ServeSuggest`) from the in-memory index.
+func renderSuggest(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	
+	err := initDb(logging.NewRecordingLogger(ctx, false))
+	var idx *api.SuggestIndex
+	if err == nil {
+		_, idx, err = currentCatalog(ctx)
+	}
+	if err != nil {
+		ctx.Errorf("ERROR: %+v", err)
+		api.WriteError(w, http.StatusInternalServerError, "internal_error", "The request could not be served")
+		return
+	}
+	api.ServeSuggest(w, r, idx)
+}
+
+// TODO Have one endpoint with *all* data (the API) and remove this one.
 
 func renderDataJson(w http.ResponseWriter, r *http.Request) {
 	preventCaching(w);
 	
 	ctx := appengine.NewContext(r)
 	
-	movies, err := sqldb.LoadMovies(db, ctx)
+	movies, err := sqldb.LoadMovies(db, parseMovieFilter(r), ctx)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
@@ -256,25 +839,22 @@ func renderUpdate(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
 	log := logging.NewRecordingLogger(ctx, false)
 	
-	var err error
-	defer recordInitUpdate(err, log)
-	err = update(w, r, log)
+	report, err := update(w, r, log)
+	recordInitUpdate(err, report, log)
 	if err != nil {
 		ctx.Errorf("ERROR: %+v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
-func update(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger) error {
-	var err error
-	
+func update(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger) (*fetch.ValidationReport, error) {
 	ctx := appengine.NewContext(r)
 	
 	if r.Method != "POST" {
 		errMsg := "Cannot " + r.Method + " '/update'"
 		ctx.Errorf(errMsg)
 		http.Error(w, errMsg, http.StatusMethodNotAllowed)
-		return nil
+		return nil, nil
 	}
 	
 	// TODO Add timestamp(s) to DB for locking to work across instances.
@@ -282,53 +862,53 @@ func update(w http.ResponseWriter, r *http.Request, log *logging.RecordingLogger
 	data.InitUpdateMutex.Lock()
 	defer data.InitUpdateMutex.Unlock()
 	
-	movies, err := fetch.FetchFromUrl(config.ServiceUrl(), ctx, log)
+	batch, err := pipeline.FromUrl(config.ServiceUrl(), db, ctx).Run(log)
+	report := batch.Report
 	if err != nil {
-		return err
-	}
-	if err := sqldb.InitTablesAndStoreMovies(db, movies, log); err != nil {
-		return err
+		return report, err
 	}
+	movies := batch.Movies
 	
-	// Fetch movie data.
+	// Fetch movie data. Expired data is refreshed by a background task.
 	// TODO This information should be fetched on demand (as location data is) or also fetched on initialization.
-	movieTitleInfoMap, err := sqldb.LoadMovieInfoJsons(db, log)
-	if err != nil {
-		return err
+	if err := data.FetchMissingMovieMetadata(db, metadataProvider, movies, ctx, log); err != nil {
+		return report, err
 	}
 	
-	movieTitleInfo := make(map[string]string)
-	for _, movie := range movies {
-		movieTitle := movie.Title
-		if _, exists := movieTitleInfoMap[movieTitle]; exists {
-			// Info already in DB.
-			continue
-		}
-		
-		infoJson, err := fetch.FetchMovieInfo(movieTitle, ctx, log)
-		if err != nil {
-			return err
-		}
-		
-		info := &struct {
-			Response string
-		}{}
-		
-		json.Unmarshal([]byte(infoJson), info)
-		if info.Response != "True" {
-			infoJson = "";
-		}
-		
-		movieTitleInfo[movieTitle] = infoJson
-	}
+	http.Redirect(w, r, "", http.StatusFound)
+	return report, nil
+}
+
+// Continue the background geocoding job (requested by cron).
+func renderGeocodeTask(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	log := logging.NewRecordingLogger(ctx, false)
 	
-	// Store movie data.
-	if err := sqldb.StoreMovieInfo(db, movieTitleInfo, log); err != nil {
-		return err
+	job, err := data.RunGeocodeJob(db, geocoders, ctx, log)
+	if err != nil {
+		ctx.Errorf("ERROR: %+v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+	if job == nil {
+		fmt.Fprintln(w, "Geocoding job has not been started")
+		return
+	}
+	fmt.Fprintf(w, "Geocoding job processed %d of %d location names\n", job.Processed, job.Total)
+}
+
+// Refresh expired movie info (requested by cron).
+func renderRefreshMovieInfoTask(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	log := logging.NewRecordingLogger(ctx, false)
 	
-	http.Redirect(w, r, "", http.StatusFound)
-	return nil
+	refreshed, failed, err := data.RefreshMovieMetadata(db, metadataProvider, ctx, log)
+	if err != nil {
+		ctx.Errorf("ERROR: %+v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "Refreshed info of %d movies (%d failed)\n", refreshed, failed)
 }
 
 func renderStatus(w http.ResponseWriter, r *http.Request) {
@@ -361,6 +941,8 @@ func status(w http.ResponseWriter, r *http.Request, logger *logging.RecordingLog
 	ct := int64(0)
 	it := int64(0)
 	
+	var geocodeJob *types.GeocodeJob
+	
 	initialized, err := data.IsInitialized(db)
 	if err != nil {
 		return err
@@ -409,6 +991,11 @@ func status(w http.ResponseWriter, r *http.Request, logger *logging.RecordingLog
 			return err
 		}
 		it = sw.ElapsedTimeMillis(true)
+		
+		geocodeJob, err = sqldb.LoadGeocodeJob(db, logger)
+		if err != nil {
+			return err
+		}
 	}
 	
 	dt := sw.TotalElapsedTimeMillis()
@@ -430,7 +1017,14 @@ func status(w http.ResponseWriter, r *http.Request, logger *logging.RecordingLog
 		InfoTime         int64
 		RecordedErr      error
 		RecordedLog      []string
-	}{sw.InitTime.String(), dt, mc, mt, ac, at, lc, lt, rc, rt, cc, ct, ic, it, recordedError, recordedLog}
+		RecordedReport   *fetch.ValidationReport
+		HostStatuses     []fetch.HostStatus
+		RateLimiters     []fetch.RateLimiterStatus
+		GeocodeJob       *types.GeocodeJob
+	}{
+		sw.InitTime.String(), dt, mc, mt, ac, at, lc, lt, rc, rt, cc, ct, ic, it,
+		recordedError, recordedLog, recordedReport, fetch.HostStatuses(), fetch.RateLimiterStatuses(), geocodeJob,
+	}
 	
 	ctx := appengine.NewContext(r)
 	templateData := tpl.NewTemplateData(ctx, logger, args)
@@ -438,6 +1032,21 @@ func status(w http.ResponseWriter, r *http.Request, logger *logging.RecordingLog
 	return tpl.Render(w, tpl.Status, templateData)
 }
 
+func renderReportJson(w http.ResponseWriter, r *http.Request) {
+	preventCaching(w);
+	
+	if recordedReport == nil {
+		http.Error(w, "No validation report has been recorded by this instance", http.StatusNotFound)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=validation-report.json")
+	if err := json.NewEncoder(w).Encode(recordedReport); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func renderPing(w http.ResponseWriter, r *http.Request) {
 	if err := ping(w, r); err != nil {
 		ctx := appengine.NewContext(r)
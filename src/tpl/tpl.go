@@ -4,9 +4,11 @@ import (
 	"src/config"
 	"src/logging"
 	"strings"
+	"fmt"
 	"html/template"
 	"net/http"
 	"reflect"
+	"time"
 	"appengine"
 )
 
@@ -42,7 +44,7 @@ type TemplateData struct {
 
 func NewTemplateData(ctx appengine.Context, logger *logging.RecordingLogger, data interface{}) TemplateData {
 	version := appengine.VersionID(ctx)
-	log := logger.Entries
+	log := logger.Entries()
 	return TemplateData{Version: version, Log: log, Data: data}
 }
 
@@ -53,15 +55,52 @@ func Render(w http.ResponseWriter, tpl *template.Template, data TemplateData) er
 var About = compile("about", template.FuncMap{})
 
 var Movie = compile("movie", template.FuncMap{
-	"field": func (field string) template.HTML {
-		if field == "" || field == "N/A" {
-			return "<i>N/A</i>"
+	"field": field,
+	"list": func (values []string) template.HTML {
+		return field(strings.Join(values, ", "))
+	},
+	"join": func (values []string) string {
+		return strings.Join(values, ", ")
+	},
+	"source": sourceLabel,
+	"age": func (since time.Time, now time.Time) string {
+		d := now.Sub(since)
+		switch {
+		case d < time.Hour:
+			return fmt.Sprintf("%d minutes", int(d / time.Minute))
+		case d < 48 * time.Hour:
+			return fmt.Sprintf("%d hours", int(d / time.Hour))
+		}
+		return fmt.Sprintf("%d days", int(d / (24 * time.Hour)))
+	},
+	"percent": func(score float64) string {
+		return fmt.Sprintf("%.0f%%", 100 * score)
+	},
+	"number": func (value interface{}) template.HTML {
+		str := fmt.Sprint(value)
+		if str == "0" {
+			str = ""
 		}
-		// Escape manually.
-		return template.HTML(template.HTMLEscapeString(field))
+		return field(str)
 	},
 })
 
+// Label of a source of reconciled movie fields.
+func sourceLabel(source string) string {
+	if source == "datasf" {
+		return "DataSF"
+	}
+	return "metadata provider"
+}
+
+func field(value string) template.HTML {
+	if value == "" {
+		return "<i>N/A</i>"
+	}
+	// Escape manually.
+	return template.HTML(template.HTMLEscapeString(value))
+}
+
 var Movies = compile("movies", template.FuncMap{
 	"join": func(ss []string) string {
 		switch len(ss) {
@@ -82,3 +121,28 @@ var Movies = compile("movies", template.FuncMap{
 var Status = compile("status", template.FuncMap{})
 
 var Ping = compile("ping", template.FuncMap{})
+
+var Merges = compile("merges", template.FuncMap{
+	"percent": func(score float64) string {
+		return fmt.Sprintf("%.0f%%", 100 * score)
+	},
+})
+
+var GeocodeFailures = compile("geocode_failures", template.FuncMap{})
+
+var CoordinateOverrides = compile("coordinate_overrides", template.FuncMap{})
+
+var MoviePins = compile("movie_pins", template.FuncMap{})
+
+var CreditDiscrepancies = compile("credit_discrepancies", template.FuncMap{
+	"join": func (values []string) string {
+		return strings.Join(values, ", ")
+	},
+	"source": sourceLabel,
+})
+
+var GeocodeReviews = compile("geocode_reviews", template.FuncMap{
+	"label": func(i int) string {
+		return string('A' + rune(i))
+	},
+})
@@ -1,41 +1,77 @@
 'use strict';
 
 jQuery(function ($, undefined) {
-	var $select = $('<select>', {
-		'data-placeholder': "Select a movie"
-	}).append('<option>');
+	var $input = $('<input>', {
+		type: 'search',
+		placeholder: "Search movies, people, and places"
+	});
+	var $results = $('<ul>', {
+		'class': 'menu vertical'
+	}).css({
+		position: 'absolute',
+		'z-index': 10,
+		background: 'white',
+		'min-width': '100%'
+	}).hide();
 	
-	$.get(
-		'/data',
-		function (data, status) {
-			if (status !== 'success') {
-				console.log("AJAX ERROR");
-				return;
-			}
-			
-			$.each(data, function (i, d) {
-				var id = d.Id;
-				var title = d.Movie.Title;
-				
-				var $option = $('<option>', {
-					'data-id': id
-				}).text(title);
-				
-				$select.append($option);
-			});
-			
-			$('#loading').replaceWith($select);
-			
-			$select.chosen({
-				no_results_text: "No movies match"
-			});
-		},
-		'json'
-	);
+	var labels = {
+		movie: "Movie",
+		person: "Person",
+		location: "Location"
+	};
 	
-	$select.change(function (e) {
-		var $option = $(this).find(':selected');
-		var id = $option.attr('data-id');
-		window.location = '/movie/'+id;
-	})
+	function show(items) {
+		$results.empty();
+		$.each(items, function (i, item) {
+			$results.append($('<li>').append(item));
+		});
+		$results.toggle(items.length > 0);
+	}
+	
+	function movieLink(id, title) {
+		return $('<a>', {
+			href: '/movie/' + id
+		}).text(title);
+	}
+	
+	// People and locations have no pages, so selecting them lists their movies.
+	function showMovies(path) {
+		$.getJSON(path, function (resource) {
+			show($.map(resource.movies, function (m) {
+				var title = m.title;
+				if (m.release_year) {
+					title += ' (' + m.release_year + ')';
+				}
+				return movieLink(m.id, title);
+			}));
+		});
+	}
+	
+	var pending;
+	$input.on('input', function () {
+		var q = $input.val();
+		if (pending) {
+			pending.abort();
+		}
+		if (!q.trim()) {
+			show([]);
+			return;
+		}
+		pending = $.getJSON('/api/suggest', {q: q}, function (suggestions) {
+			show($.map(suggestions, function (s) {
+				if (s.type === 'movie') {
+					return movieLink(s.id, s.label);
+				}
+				var path = s.type === 'person' ? '/api/v1/people/' : '/api/v1/locations/';
+				return $('<a>', {
+					href: '#'
+				}).text(s.label + ' · ' + labels[s.type]).click(function (e) {
+					e.preventDefault();
+					showMovies(path + s.id);
+				});
+			}));
+		});
+	});
+	
+	$('#loading').replaceWith($('<div>').css('position', 'relative').append($input, $results));
 });
@@ -17,6 +17,7 @@ function initMap() {
 		var name = $location.data('name');
 		var lat = $location.data('lat');
 		var lng = $location.data('lng');
+		var approximate = $location.data('approximate');
 		
 		if (!lat || !lng) {
 			$location.addClass('warning').attr('title', 'Could not find coordinates for this location...');
@@ -24,8 +25,10 @@ function initMap() {
 		}
 		
 		var marker = new google.maps.Marker({
-			title: name,
+			title: approximate ? name + ' (approximate location)' : name,
 			position: {lat: lat, lng: lng},
+			// Low-confidence coordinates (like the center of a neighborhood) are shown faded.
+			opacity: approximate ? 0.5 : 1.0,
 			// animation: google.maps.Animation.DROP,
 			map: map
 		});
@@ -0,0 +1,50 @@
+'use strict';
+
+function initReviewMaps() {
+	var sf = {lat: 37.749, lng: -122.439};
+	
+	$('.review').each(function () {
+		var $review = $(this);
+		var map = new google.maps.Map($review.find('.review-map')[0], {
+			zoom: 12,
+			center: sf
+		});
+		
+		var bounds = new google.maps.LatLngBounds();
+		$review.find('.candidate').each(function () {
+			var $candidate = $(this);
+			var marker = new google.maps.Marker({
+				label: $candidate.data('label'),
+				position: {lat: $candidate.data('lat'), lng: $candidate.data('lng')},
+				map: map
+			});
+			bounds.extend(marker.getPosition());
+		});
+		if (!bounds.isEmpty()) {
+			map.fitBounds(bounds);
+			// Don't zoom in too far on a single candidate.
+			google.maps.event.addListenerOnce(map, 'bounds_changed', function () {
+				if (map.getZoom() > 16) {
+					map.setZoom(16);
+				}
+			});
+		}
+		
+		// Clicking the map fills in the coordinates of a correction.
+		var correction = null;
+		map.addListener('click', function (event) {
+			var position = event.latLng;
+			$review.find('.correction-lat').val(position.lat().toFixed(6));
+			$review.find('.correction-lng').val(position.lng().toFixed(6));
+			if (correction) {
+				correction.setPosition(position);
+			} else {
+				correction = new google.maps.Marker({
+					position: position,
+					icon: 'https://maps.google.com/mapfiles/ms/icons/green-dot.png',
+					map: map
+				});
+			}
+		});
+	});
+}